@@ -16,15 +16,92 @@ type Config struct {
 	Filters    FiltersConfig    `yaml:"filters"`
 	Processing ProcessingConfig `yaml:"processing"`
 	Tools      ToolsConfig      `yaml:"tools"`
+	Server     ServerConfig     `yaml:"server"`
+	Rules      RulesConfig      `yaml:"rules"`
+	// Pager overrides the command SetTitleAction/SetOcrInContentAction pipe
+	// their pre-confirmation document preview table into (see
+	// processor.resolvePagerCommand). Empty falls back to $PAGER, then
+	// "less -R", then no paging at all if neither resolves to a binary on
+	// PATH. Also see the --no-pager flag, which disables paging regardless
+	// of this setting.
+	Pager string `yaml:"pager"`
+}
+
+// RulesConfig declares the routing rules evaluated by ApplyRulesAction (see
+// internal/rules): each rule matches documents by title/content pattern (or
+// a hash partition, for splitting an otherwise-identical match across
+// several correspondents) and sets their correspondent, document type,
+// and/or tags.
+type RulesConfig struct {
+	Rules []RuleConfig `yaml:"rules"`
+}
+
+// RuleConfig matches a document against every condition in When and, if
+// they all match, applies Then. Rules are evaluated in order and the first
+// match wins, same as FilterConfig's patterns.
+type RuleConfig struct {
+	Name string            `yaml:"name"`
+	When []ConditionConfig `yaml:"when"`
+	Then ActionConfig      `yaml:"then"`
+}
+
+// ConditionConfig matches a document on Field ("title" or "content") via a
+// regex Pattern (or, with Glob set, a shell-style glob), or, with
+// Field: "hash", partitions documents into Buckets evenly-sized buckets by
+// a stable hash of the document ID and matches when it falls into Bucket.
+// The hash partition exists to split an otherwise-identical match across
+// several correspondents/tags for load balancing, not to inspect document
+// content.
+type ConditionConfig struct {
+	Field   string `yaml:"field"`
+	Pattern string `yaml:"pattern"`
+	Glob    bool   `yaml:"glob"`
+	Buckets int    `yaml:"buckets"`
+	Bucket  int    `yaml:"bucket"`
+}
+
+// ActionConfig is applied to every document a rule's When conditions all
+// match. Correspondent, DocumentType, and each entry of Tags may use
+// {{title}}, {{content}}, {{year}}, {{extract:name}} (a named regex capture
+// group from a matching condition), or {{llm:"prompt"}} (asks the LLM
+// client) as a placeholder; see internal/rules for the full set.
+type ActionConfig struct {
+	Correspondent string   `yaml:"correspondent"`
+	DocumentType  string   `yaml:"document_type"`
+	Tags          []string `yaml:"tags"`
+}
+
+// ServerConfig configures the long-running webhook server started by
+// --serve. Addr defaults to ":8085" and Workers to 1 when unset.
+type ServerConfig struct {
+	Addr    string `yaml:"addr"`
+	Workers int    `yaml:"workers"`
 }
 type ToolsConfig struct {
 	ImagemagickForWindows ImagemagickConfig `yaml:"imagemagick-for-windows"`
+	// Renderer selects the PDF render backend (see internal/render):
+	// "imagemagick", "poppler", "mupdf", "pdfcpu", or "auto". Empty
+	// auto-detects the first available one at startup, preferring mupdf
+	// since it needs no external binary. "auto" instead renders with mupdf
+	// and falls back to imagemagick on a per-page basis, so a single PDF
+	// using a feature mupdf can't handle doesn't fail the whole batch.
+	Renderer      string        `yaml:"renderer"`
+	RenderOptions RenderOptions `yaml:"render_options"`
 }
 
 type ImagemagickConfig struct {
 	FullPath string `yaml:"fullpath"`
 }
 
+// RenderOptions controls how a PDF page is rasterized before OCR, trading
+// off OCR legibility against LLM image-token cost. A zero value for any
+// field falls back to a built-in default.
+type RenderOptions struct {
+	DPI            int `yaml:"dpi"`
+	TargetLongEdge int `yaml:"target_long_edge"`
+	JPEGQuality    int `yaml:"jpeg_quality"`
+}
+
 type PaperlessConfig struct {
 	API struct {
 		BaseURL    string `yaml:"base_url"`
@@ -37,13 +114,18 @@ type PaperlessConfig struct {
 
 type LLMConfig struct {
 	API struct {
-		BaseURL  string `yaml:"base_url"`
-		Endpoint string `yaml:"endpoint"`
-		Timeout  int    `yaml:"timeout"` // Timeout in seconds for LLM API requests
+		BaseURL     string           `yaml:"base_url"`
+		Endpoint    string           `yaml:"endpoint"`
+		Timeout     int              `yaml:"timeout"` // Timeout in seconds for LLM API requests
+		Debug       bool             `yaml:"debug"`
+		DebugFolder string           `yaml:"debug_folder"`
+		Provider    string           `yaml:"provider"` // Backend provider: "openai" (default), "ollama" or "llamacpp"
+		Resilience  ResilienceConfig `yaml:"resilience"`
 	} `yaml:"api"`
 	Models struct {
 		TitleGeneration string `yaml:"title_generation"`
 		OCR             string `yaml:"ocr"`
+		EmbeddingModel  string `yaml:"embedding_model"`
 	} `yaml:"models"`
 	Prompts struct {
 		TitleGeneration string `yaml:"title_generation"`
@@ -52,8 +134,9 @@ type LLMConfig struct {
 }
 
 type FiltersConfig struct {
-	Title   FilterConfig `yaml:"title"`
-	Content FilterConfig `yaml:"content"`
+	Title    FilterConfig         `yaml:"title"`
+	Content  FilterConfig         `yaml:"content"`
+	Semantic SemanticFilterConfig `yaml:"semantic"`
 }
 
 type FilterConfig struct {
@@ -61,10 +144,141 @@ type FilterConfig struct {
 	Pattern     []string `yaml:"pattern"`
 }
 
+// SemanticFilterConfig configures FilterTypeSemantic: documents are matched
+// by embedding similarity instead of a regex. Queries are free-text
+// descriptions ("invoices from Deutsche Bahn"); ExampleDocumentIDs names
+// already-fetched documents whose title+content is embedded as an additional
+// query. Threshold is the minimum cosine similarity (against the closest
+// query) a document must reach to be kept; Window caps how many characters
+// of a document go into a single embedding call before it's split into
+// chunks and mean-pooled. A zero Threshold or Window falls back to a
+// built-in default (see internal.filterBySemanticSimilarity).
+type SemanticFilterConfig struct {
+	Queries            []string `yaml:"queries"`
+	ExampleDocumentIDs []int    `yaml:"example_document_ids"`
+	Threshold          float64  `yaml:"threshold"`
+	Window             int      `yaml:"window"`
+}
+
 type ProcessingConfig struct {
 	TitleGeneration struct {
 		TruncateCharactersOfContent int `yaml:"truncate_characters_of_content"`
 	} `yaml:"title_generation"`
+	Budgets        BudgetsConfig        `yaml:"budgets"`
+	Tagging        TaggingConfig        `yaml:"tagging"`
+	OCR            OCRConfig            `yaml:"ocr"`
+	Workers        WorkersConfig        `yaml:"workers"`
+	Cache          CacheConfig          `yaml:"cache"`
+	Classification ClassificationConfig `yaml:"classification"`
+	DryRun         DryRunConfig         `yaml:"dry_run"`
+}
+
+// DryRunConfig controls how --dry-run previews pending title/content
+// changes before main.go writes its ".diff.jsonl" report (see
+// processor.renderChangesTable). ContextChars caps how much of each old/new
+// value the unified-diff preview shows, since a full OCR'd document's
+// content would otherwise scroll the whole change off-screen.
+type DryRunConfig struct {
+	ContextChars int `yaml:"context_chars"`
+}
+
+// ClassificationConfig controls ClassifyDocumentsAction, the LLM-driven
+// counterpart to the routing rules in internal/rules. Threshold is the
+// minimum confidence score (0-1) LLMClient.ClassifyDocument must report
+// before the processor writes its tags/correspondent/document type to
+// Paperless; below it, the document is left untouched rather than risk
+// misclassifying it. 0 falls back to a built-in default.
+type ClassificationConfig struct {
+	Threshold float64 `yaml:"threshold"`
+}
+
+// CacheConfig controls the persistent, content-addressed cache for OCR and
+// title-generation results (see internal/cache). Disabled can also be set
+// at runtime via the --no-cache flag, without editing the config file.
+type CacheConfig struct {
+	Disabled bool `yaml:"disabled"`
+	// Backend selects the Store implementation: "bbolt" (default, a local
+	// file at Path) or "redis" (shared, at RedisAddr).
+	Backend   string `yaml:"backend"`
+	Path      string `yaml:"path"`
+	RedisAddr string `yaml:"redis_addr"`
+}
+
+// WorkersConfig controls how many goroutines run concurrently at each stage
+// of the document processing pipeline (see processor/pipeline.go). A value
+// of 0 falls back to a built-in default for that stage.
+type WorkersConfig struct {
+	Download int `yaml:"download"`
+	Render   int `yaml:"render"`
+	LLM      int `yaml:"llm"`
+	// MaxParallel overrides Download/Render/LLM uniformly for a caller who
+	// wants one fan-out knob instead of tuning each stage separately; it's
+	// ignored for any stage that already sets its own value. 0 leaves the
+	// per-stage defaults untouched. See ActionExecutor.SetConcurrency for
+	// the equivalent programmatic override.
+	MaxParallel int `yaml:"max_parallel"`
+}
+
+// BudgetsConfig caps LLM token spend. A value of 0 disables that particular
+// limit. LedgerPath, if set, receives one JSON line per LLM call recording
+// the tokens consumed, so spend can be audited after the fact.
+type BudgetsConfig struct {
+	MaxTokensPerDocument int    `yaml:"max_tokens_per_document"`
+	MaxTokensPerDay      int    `yaml:"max_tokens_per_day"`
+	LedgerPath           string `yaml:"ledger_path"`
+}
+
+// TaggingConfig controls the embeddings-based auto-tagging subsystem in
+// internal/tagger. StorePath is where the tag centroids are persisted;
+// Threshold is the minimum cosine similarity a document's content embedding
+// must reach against a tag's centroid for that tag to be assigned.
+type TaggingConfig struct {
+	StorePath string  `yaml:"store_path"`
+	Threshold float64 `yaml:"threshold"`
+}
+
+// OCRConfig registers additional JSON schemas for MakeOcrStructured, beyond
+// the built-in invoice/receipt/contract/letter schemas. Schemas maps a
+// schema name to the path of a JSON file in the same
+// {"name", "schema", "strict"} shape as the built-in ones.
+type OCRConfig struct {
+	Schemas map[string]string `yaml:"schemas"`
+	// Preprocess enables processor.OcrPipeline's binarization-threshold
+	// trial: each page is OCR'd at a few fixed thresholds and the
+	// best-scoring result is kept, at the cost of several extra LLM calls
+	// per page. Disabled by default since it multiplies OCR cost.
+	Preprocess bool `yaml:"preprocess"`
+	// HOCRDir, if set, makes processor.OcrPipeline write a sidecar .hocr
+	// file per document under this directory alongside the plain-text
+	// content written to Paperless.
+	HOCRDir string `yaml:"hocr_dir"`
+	// Concurrency bounds how many pages of a single document
+	// processor.OcrPipeline renders and OCRs at once. 0 falls back to a
+	// built-in default. This is separate from processing.workers.render/llm,
+	// which bound concurrency across documents, not within one.
+	Concurrency int `yaml:"concurrency"`
+}
+
+// ResilienceConfig tunes how doRequestWithRetry retries failed LLM API
+// calls. A zero value for any field falls back to a sane built-in default.
+type ResilienceConfig struct {
+	MaxRetries     int                  `yaml:"max_retries"`
+	CircuitBreaker CircuitBreakerConfig `yaml:"circuit_breaker"`
+	RateLimit      RateLimitConfig      `yaml:"rate_limit"`
+}
+
+// CircuitBreakerConfig opens the per-client circuit breaker after
+// FailureThreshold consecutive failures, rejecting requests for
+// CooldownSeconds before letting a single probe request through.
+type CircuitBreakerConfig struct {
+	FailureThreshold int `yaml:"failure_threshold"`
+	CooldownSeconds  int `yaml:"cooldown_seconds"`
+}
+
+// RateLimitConfig caps outgoing LLM API calls via a token bucket. 0 disables
+// the limiter.
+type RateLimitConfig struct {
+	RequestsPerMinute int `yaml:"requests_per_minute"`
 }
 
 func LoadConfig(configPath string) (*Config, error) {
@@ -121,9 +335,16 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("llm.prompts.ocr is required")
 	}
 
-	// Validate ImageMagick availability
-	if err := c.validateImageMagick(); err != nil {
-		return fmt.Errorf("imagemagick validation failed: %w", err)
+	// Validate ImageMagick availability, but only when it's explicitly the
+	// configured renderer. An unset tools.renderer auto-detects across all
+	// backends (see internal/render.New), and tools.renderer:
+	// mupdf/poppler/pdfcpu don't need ImageMagick installed at all. Either
+	// way, render.New reports a clear error of its own if no backend turns
+	// out to be available at render time.
+	if c.Tools.Renderer == "imagemagick" {
+		if err := c.validateImageMagick(); err != nil {
+			return fmt.Errorf("imagemagick validation failed: %w", err)
+		}
 	}
 
 	return nil
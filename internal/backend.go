@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+)
+
+// Backend is the provider-specific transport used by LLMClient to talk to
+// whatever server is configured in config.LLM.API.BaseURL. Each capability
+// the client exposes (title generation, OCR, model listing, connectivity
+// checks) has a matching Backend method, so adding a new provider means
+// adding a new implementation rather than touching callers. Every method
+// takes ctx so a cancelled batch run (see main.go's signal.NotifyContext
+// wiring) aborts the in-flight request instead of running to completion.
+type Backend interface {
+	GenerateTitle(ctx context.Context, documentID int, content string) (CaptionResponse, error)
+	OCR(ctx context.Context, documentID int, imageData []byte) (string, error)
+	Embed(ctx context.Context, text string) ([]float32, error)
+	ListModels(ctx context.Context) ([]string, error)
+	CheckConnection(ctx context.Context) error
+}
+
+// Provider identifies which wire protocol a Backend speaks.
+type Provider string
+
+const (
+	ProviderOpenAI   Provider = "openai"
+	ProviderOllama   Provider = "ollama"
+	ProviderLlamaCpp Provider = "llamacpp"
+)
+
+// newBackend selects the Backend implementation for c based on
+// c.config.LLM.API.Provider, defaulting to the OpenAI-compatible chat
+// completions API (today's behavior) when unset.
+func newBackend(c *LLMClient) (Backend, error) {
+	switch Provider(c.config.LLM.API.Provider) {
+	case "", ProviderOpenAI:
+		return &openAIBackend{client: c}, nil
+	case ProviderOllama:
+		return &ollamaBackend{client: c}, nil
+	case ProviderLlamaCpp:
+		return &llamaCppBackend{client: c}, nil
+	default:
+		return nil, fmt.Errorf("unknown llm.api.provider: %q", c.config.LLM.API.Provider)
+	}
+}
@@ -0,0 +1,165 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// llamaCppBackend talks to llama.cpp's server /completion endpoint. It only
+// supports text completion, so OCR (which needs a vision-capable chat
+// endpoint) is not available through this backend. Title generation is
+// constrained to valid JSON via a GBNF grammar passed in the "grammar"
+// field, which llama.cpp enforces token-by-token during sampling.
+type llamaCppBackend struct {
+	client *LLMClient
+}
+
+// captionGrammar is a GBNF grammar that constrains the model's output to
+// the CaptionResponse JSON shape, so the response can always be unmarshaled
+// without a response_format/json_schema API to lean on.
+const captionGrammar = `
+root   ::= "{" ws "\"summarize\":" ws string "," ws "\"captions\":" ws captions ws "}"
+captions ::= "[" ws (caption ("," ws caption)*)? ws "]"
+caption ::= "{" ws "\"caption\":" ws string "," ws "\"score\":" ws number ws "}"
+string ::= "\"" ([^"\\])* "\""
+number ::= "-"? [0-9]+ ("." [0-9]+)?
+ws ::= [ \t\n]*
+`
+
+type llamaCppCompletionRequest struct {
+	Prompt  string `json:"prompt"`
+	Grammar string `json:"grammar,omitempty"`
+}
+
+type llamaCppCompletionResponse struct {
+	Content string `json:"content"`
+}
+
+func (b *llamaCppBackend) baseURL() string {
+	return strings.TrimSuffix(b.client.config.LLM.API.BaseURL, "/")
+}
+
+func (b *llamaCppBackend) complete(ctx context.Context, prompt, grammar string) (string, error) {
+	reqBody, err := json.Marshal(llamaCppCompletionRequest{Prompt: prompt, Grammar: grammar})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	ctx, cancel := b.client.withDeadline(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", b.baseURL()+"/completion", bytes.NewReader(reqBody))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.client.doRequestWithRetry(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("unexpected status code: %d, response: %s", resp.StatusCode, string(body))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var completion llamaCppCompletionResponse
+	if err := json.Unmarshal(body, &completion); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return completion.Content, nil
+}
+
+// GenerateTitle implements Backend. documentID is accepted for interface
+// parity with the other backends but unused: llama.cpp's /completion
+// response carries no token-usage block, so there is nothing to record in
+// the client's TokenLedger here.
+func (b *llamaCppBackend) GenerateTitle(ctx context.Context, documentID int, content string) (CaptionResponse, error) {
+	if content == "" {
+		return CaptionResponse{
+			Summarize: "Empty document content",
+			Captions:  []Caption{{Caption: "EMPTY_CONTENT", Score: 0.0}},
+		}, nil
+	}
+
+	if b.client.config.Processing.TitleGeneration.TruncateCharactersOfContent > 0 &&
+		len(content) > b.client.config.Processing.TitleGeneration.TruncateCharactersOfContent {
+		content = content[:b.client.config.Processing.TitleGeneration.TruncateCharactersOfContent]
+	}
+
+	prompt := strings.ReplaceAll(b.client.config.LLM.Prompts.TitleGeneration, "{content}", content)
+
+	response, err := b.complete(ctx, prompt, captionGrammar)
+	if err != nil {
+		return CaptionResponse{}, fmt.Errorf("failed to generate title: %w", err)
+	}
+
+	var captionResp CaptionResponse
+	if err := json.Unmarshal([]byte(response), &captionResp); err != nil {
+		return CaptionResponse{
+			Summarize: "Failed to parse LLM response",
+			Captions:  []Caption{{Caption: response, Score: 0.0}},
+		}, nil
+	}
+
+	if len(captionResp.Captions) == 0 {
+		return CaptionResponse{
+			Summarize: captionResp.Summarize,
+			Captions:  []Caption{{Caption: response, Score: 0.0}},
+		}, nil
+	}
+
+	return captionResp, nil
+}
+
+// OCR implements Backend; see the documentID note on GenerateTitle.
+func (b *llamaCppBackend) OCR(ctx context.Context, documentID int, imageData []byte) (string, error) {
+	return "", fmt.Errorf("llamacpp backend does not support OCR: /completion has no vision input, configure llm.api.provider: openai or ollama for OCR")
+}
+
+// Embed implements Backend; see the OCR note above - /completion has no
+// embeddings endpoint on the bare llama.cpp server.
+func (b *llamaCppBackend) Embed(ctx context.Context, text string) ([]float32, error) {
+	return nil, fmt.Errorf("llamacpp backend does not support embeddings: configure llm.api.provider: openai for embeddings")
+}
+
+func (b *llamaCppBackend) ListModels(ctx context.Context) ([]string, error) {
+	// llama.cpp serves a single model per process; report it from config
+	// since there is no /v1/models-equivalent on the bare /completion server.
+	return []string{b.client.config.LLM.Models.TitleGeneration}, nil
+}
+
+func (b *llamaCppBackend) CheckConnection(ctx context.Context) error {
+	ctx, cancel := b.client.withDeadline(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", b.baseURL()+"/health", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := b.client.doRequestWithRetry(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,238 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ollamaBackend talks to Ollama's native API (/api/generate, /api/chat,
+// /api/tags). Ollama streams NDJSON (one JSON object per line) rather than
+// a single response body, and has no response_format/json_schema support,
+// so structured title generation is done by instructing the model to
+// reply with JSON and parsing the accumulated text on completion.
+type ollamaBackend struct {
+	client *LLMClient
+}
+
+type ollamaChatMessage struct {
+	Role    string   `json:"role"`
+	Content string   `json:"content"`
+	Images  []string `json:"images,omitempty"`
+}
+
+type ollamaChatRequest struct {
+	Model    string              `json:"model"`
+	Messages []ollamaChatMessage `json:"messages"`
+	Stream   bool                `json:"stream"`
+	Format   string              `json:"format,omitempty"`
+}
+
+type ollamaChatChunk struct {
+	Message ollamaChatMessage `json:"message"`
+	Done    bool              `json:"done"`
+}
+
+type ollamaTagsResponse struct {
+	Models []struct {
+		Name string `json:"name"`
+	} `json:"models"`
+}
+
+func (b *ollamaBackend) baseURL() string {
+	return strings.TrimSuffix(b.client.config.LLM.API.BaseURL, "/")
+}
+
+// postChat sends a /api/chat request and accumulates the NDJSON message
+// deltas into a single response string.
+func (b *ollamaBackend) postChat(ctx context.Context, model, prompt string, images []string, asJSON bool) (string, error) {
+	chatReq := ollamaChatRequest{
+		Model: model,
+		Messages: []ollamaChatMessage{
+			{Role: "user", Content: prompt, Images: images},
+		},
+		Stream: true,
+	}
+	if asJSON {
+		chatReq.Format = "json"
+	}
+
+	reqBody, err := json.Marshal(chatReq)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	ctx, cancel := b.client.withDeadline(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", b.baseURL()+"/api/chat", bytes.NewReader(reqBody))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.client.doRequestWithRetry(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("unexpected status code: %d, response: %s", resp.StatusCode, string(body))
+	}
+
+	var sb strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var chunk ollamaChatChunk
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return "", fmt.Errorf("failed to parse ollama response line: %w", err)
+		}
+		sb.WriteString(chunk.Message.Content)
+		if chunk.Done {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	return sb.String(), nil
+}
+
+// GenerateTitle implements Backend. documentID is accepted for interface
+// parity with the other backends but unused: Ollama's /api/chat response
+// carries no token-usage block, so there is nothing to record in the
+// client's TokenLedger here.
+func (b *ollamaBackend) GenerateTitle(ctx context.Context, documentID int, content string) (CaptionResponse, error) {
+	if content == "" {
+		return CaptionResponse{
+			Summarize: "Empty document content",
+			Captions:  []Caption{{Caption: "EMPTY_CONTENT", Score: 0.0}},
+		}, nil
+	}
+
+	if b.client.config.Processing.TitleGeneration.TruncateCharactersOfContent > 0 &&
+		len(content) > b.client.config.Processing.TitleGeneration.TruncateCharactersOfContent {
+		content = content[:b.client.config.Processing.TitleGeneration.TruncateCharactersOfContent]
+	}
+
+	prompt := b.client.config.LLM.Prompts.TitleGeneration
+	prompt = strings.ReplaceAll(prompt, "{content}", content)
+	prompt += "\n\nRespond with a JSON object matching {\"summarize\": string, \"captions\": [{\"caption\": string, \"score\": number}]}."
+
+	response, err := b.postChat(ctx, b.client.config.LLM.Models.TitleGeneration, prompt, nil, true)
+	if err != nil {
+		return CaptionResponse{}, fmt.Errorf("failed to generate title: %w", err)
+	}
+
+	var captionResp CaptionResponse
+	if err := json.Unmarshal([]byte(response), &captionResp); err != nil {
+		return CaptionResponse{
+			Summarize: "Failed to parse LLM response",
+			Captions:  []Caption{{Caption: response, Score: 0.0}},
+		}, nil
+	}
+
+	if len(captionResp.Captions) == 0 {
+		return CaptionResponse{
+			Summarize: captionResp.Summarize,
+			Captions:  []Caption{{Caption: response, Score: 0.0}},
+		}, nil
+	}
+
+	return captionResp, nil
+}
+
+// OCR implements Backend; see the documentID note on GenerateTitle.
+func (b *ollamaBackend) OCR(ctx context.Context, documentID int, imageData []byte) (string, error) {
+	image := base64.StdEncoding.EncodeToString(imageData)
+	response, err := b.postChat(ctx, b.client.config.LLM.Models.OCR, b.client.config.LLM.Prompts.OCR, []string{image}, false)
+	if err != nil {
+		return "", fmt.Errorf("failed to extract content: %w", err)
+	}
+	return response, nil
+}
+
+// Embed implements Backend. Ollama's /api/embeddings endpoint uses a
+// different request/response shape than the OpenAI-compatible one this
+// client speaks elsewhere, so rather than add a second code path for a
+// capability internal/tagger doesn't yet call through this provider, report
+// it as unsupported until a caller needs it.
+func (b *ollamaBackend) Embed(ctx context.Context, text string) ([]float32, error) {
+	return nil, fmt.Errorf("ollama backend does not support embeddings: configure llm.api.provider: openai for embeddings")
+}
+
+func (b *ollamaBackend) ListModels(ctx context.Context) ([]string, error) {
+	ctx, cancel := b.client.withDeadline(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", b.baseURL()+"/api/tags", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := b.client.doRequestWithRetry(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var tagsResp ollamaTagsResponse
+	if err := json.Unmarshal(body, &tagsResp); err != nil {
+		return nil, fmt.Errorf("failed to parse tags response: %w", err)
+	}
+
+	ids := make([]string, 0, len(tagsResp.Models))
+	for _, m := range tagsResp.Models {
+		ids = append(ids, m.Name)
+	}
+	return ids, nil
+}
+
+func (b *ollamaBackend) CheckConnection(ctx context.Context) error {
+	models, err := b.ListModels(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+
+	titleModelAvailable := false
+	contentModelAvailable := false
+	for _, id := range models {
+		if id == b.client.config.LLM.Models.TitleGeneration {
+			titleModelAvailable = true
+		}
+		if id == b.client.config.LLM.Models.OCR {
+			contentModelAvailable = true
+		}
+	}
+
+	if !titleModelAvailable {
+		return fmt.Errorf("title generation model '%s' not available, found %v", b.client.config.LLM.Models.TitleGeneration, models)
+	}
+	if !contentModelAvailable {
+		return fmt.Errorf("content extraction model '%s' not available, found %v", b.client.config.LLM.Models.OCR, models)
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package internal
+
+import "context"
+
+// openAIBackend talks to an OpenAI-compatible chat completions endpoint.
+// This is the backend used by Privatemode.ai and by any other proxy that
+// exposes /v1/chat/completions and /v1/models, and it is what LLMClient
+// spoke natively before the Backend interface was introduced.
+type openAIBackend struct {
+	client *LLMClient
+}
+
+func (b *openAIBackend) GenerateTitle(ctx context.Context, documentID int, content string) (CaptionResponse, error) {
+	return b.client.generateTitleFromContentOpenAI(ctx, documentID, content)
+}
+
+func (b *openAIBackend) OCR(ctx context.Context, documentID int, imageData []byte) (string, error) {
+	return b.client.makeOcrOpenAI(ctx, documentID, imageData)
+}
+
+func (b *openAIBackend) Embed(ctx context.Context, text string) ([]float32, error) {
+	return b.client.embedOpenAI(ctx, text)
+}
+
+func (b *openAIBackend) ListModels(ctx context.Context) ([]string, error) {
+	return b.client.listModelsOpenAI(ctx)
+}
+
+func (b *openAIBackend) CheckConnection(ctx context.Context) error {
+	return b.client.checkConnectionOpenAI(ctx)
+}
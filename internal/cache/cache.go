@@ -0,0 +1,87 @@
+// Package cache implements a persistent, content-addressed cache for LLM
+// results. Results are keyed by a hash of the inputs that determine them
+// (source bytes, model, prompt, ...), so re-running the same corpus against
+// the same prompt/model is instant and free, and only a changed input
+// invalidates the cached entry. The default Store is a single bbolt file on
+// disk; Redis can be selected instead to share a cache across machines.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+
+	"github.com/dhcgn/paperless-ngx-privatemode-ai/config"
+)
+
+var bucketName = []byte("results")
+
+// Store persists and retrieves cached results by key. Implementations must
+// be safe for concurrent use.
+type Store interface {
+	// Get returns the cached value for key, or ok == false if no entry
+	// exists.
+	Get(key string) (value []byte, ok bool, err error)
+	// Set stores value under key, overwriting any existing entry.
+	Set(key string, value []byte) error
+	// Prune deletes every cached entry and returns how many were removed.
+	Prune() (int, error)
+	Close() error
+}
+
+// New returns the Store configured by cfg, defaulting to a bbolt file at
+// cfg.Path (or "cache.db" if unset).
+func New(cfg config.CacheConfig) (Store, error) {
+	switch cfg.Backend {
+	case "", "bbolt":
+		path := cfg.Path
+		if path == "" {
+			path = "cache.db"
+		}
+		return newBoltStore(path)
+	case "redis":
+		return newRedisStore(cfg.RedisAddr)
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q", cfg.Backend)
+	}
+}
+
+// KeyForOCR hashes the inputs that determine a MakeOcr result: the source
+// PDF bytes, the page rendered, and the model/prompt used to extract it.
+func KeyForOCR(pdfBytes []byte, page int, model, prompt string) string {
+	h := sha256.New()
+	h.Write(pdfBytes)
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.Itoa(page)))
+	h.Write([]byte{0})
+	h.Write([]byte(model))
+	h.Write([]byte{0})
+	h.Write([]byte(prompt))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// KeyForEmbedding hashes the inputs that determine an Embed result: the
+// embedded text and the model used to embed it.
+func KeyForEmbedding(text, model string) string {
+	h := sha256.New()
+	h.Write([]byte(text))
+	h.Write([]byte{0})
+	h.Write([]byte(model))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// KeyForTitle hashes the inputs that determine a GenerateTitleFromContent
+// result: the document content, the model/prompt used, and how much of the
+// content is truncated before it is sent.
+func KeyForTitle(content, model, prompt string, truncateChars int) string {
+	h := sha256.New()
+	h.Write([]byte(content))
+	h.Write([]byte{0})
+	h.Write([]byte(model))
+	h.Write([]byte{0})
+	h.Write([]byte(prompt))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.Itoa(truncateChars)))
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestKeyForOCR_DeterministicAndSensitiveToInputs(t *testing.T) {
+	base := KeyForOCR([]byte("pdf-bytes"), 0, "model-a", "prompt")
+	if again := KeyForOCR([]byte("pdf-bytes"), 0, "model-a", "prompt"); again != base {
+		t.Errorf("KeyForOCR is not deterministic: %q != %q", again, base)
+	}
+	if diffPage := KeyForOCR([]byte("pdf-bytes"), 1, "model-a", "prompt"); diffPage == base {
+		t.Error("KeyForOCR ignored the page number")
+	}
+	if diffModel := KeyForOCR([]byte("pdf-bytes"), 0, "model-b", "prompt"); diffModel == base {
+		t.Error("KeyForOCR ignored the model")
+	}
+}
+
+func TestKeyForEmbedding_DeterministicAndSensitiveToInputs(t *testing.T) {
+	base := KeyForEmbedding("hello", "model-a")
+	if again := KeyForEmbedding("hello", "model-a"); again != base {
+		t.Errorf("KeyForEmbedding is not deterministic: %q != %q", again, base)
+	}
+	if diffText := KeyForEmbedding("world", "model-a"); diffText == base {
+		t.Error("KeyForEmbedding ignored the text")
+	}
+}
+
+func TestKeyForTitle_DeterministicAndSensitiveToInputs(t *testing.T) {
+	base := KeyForTitle("content", "model-a", "prompt", 1000)
+	if again := KeyForTitle("content", "model-a", "prompt", 1000); again != base {
+		t.Errorf("KeyForTitle is not deterministic: %q != %q", again, base)
+	}
+	if diffTruncate := KeyForTitle("content", "model-a", "prompt", 2000); diffTruncate == base {
+		t.Error("KeyForTitle ignored truncateChars")
+	}
+}
+
+func TestBoltStore_GetSetPrune(t *testing.T) {
+	store, err := newBoltStore(filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatalf("newBoltStore: %v", err)
+	}
+	defer store.Close()
+
+	if _, ok, err := store.Get("missing"); err != nil || ok {
+		t.Fatalf("Get(missing) = ok=%v, err=%v, want ok=false, err=nil", ok, err)
+	}
+
+	if err := store.Set("key", []byte("value")); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	value, ok, err := store.Get("key")
+	if err != nil || !ok || string(value) != "value" {
+		t.Fatalf("Get(key) = %q, ok=%v, err=%v, want \"value\", true, nil", value, ok, err)
+	}
+
+	n, err := store.Prune()
+	if err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("Prune() = %d, want 1", n)
+	}
+	if _, ok, _ := store.Get("key"); ok {
+		t.Error("entry still present after Prune")
+	}
+}
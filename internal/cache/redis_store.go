@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisStore is an optional Store backend for sharing cached results across
+// multiple machines processing the same corpus.
+type redisStore struct {
+	client *redis.Client
+}
+
+func newRedisStore(addr string) (*redisStore, error) {
+	if addr == "" {
+		return nil, fmt.Errorf("processing.cache.redis_addr is required when processing.cache.backend is \"redis\"")
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		return nil, fmt.Errorf("failed to connect to redis at %q: %w", addr, err)
+	}
+
+	return &redisStore{client: client}, nil
+}
+
+func (s *redisStore) Get(key string) ([]byte, bool, error) {
+	value, err := s.client.Get(context.Background(), key).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (s *redisStore) Set(key string, value []byte) error {
+	return s.client.Set(context.Background(), key, value, 0).Err()
+}
+
+func (s *redisStore) Prune() (int, error) {
+	ctx := context.Background()
+	keys, err := s.client.Keys(ctx, "*").Result()
+	if err != nil {
+		return 0, err
+	}
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	if err := s.client.Del(ctx, keys...).Err(); err != nil {
+		return 0, err
+	}
+	return len(keys), nil
+}
+
+func (s *redisStore) Close() error {
+	return s.client.Close()
+}
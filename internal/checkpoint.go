@@ -0,0 +1,168 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Checkpoint is the on-disk record of how far an OCR or title generation
+// batch run got; rules, metadata extraction and classification runs do not
+// record progress into it. After every document it is written to the
+// checkpoint being resumed, or else to a new file under
+// DefaultCheckpointDir; when main.go's signal.NotifyContext handler fires,
+// a copy is also written next to the config file. A subsequent run started with
+// --resume (or one that finds a matching file under DefaultCheckpointDir)
+// loads it and skips ProcessedIDs via ActionExecutor.SetResumeIDs.
+type Checkpoint struct {
+	// Action is action.Description(), so a resume attempt against a
+	// mismatched action (or FilterHash) can be caught instead of silently
+	// skipping the wrong documents.
+	Action string `json:"action"`
+	// FilterHash identifies the filter parameters (patterns, thresholds,
+	// ...) the run that wrote this checkpoint used; see HashFilterParams.
+	FilterHash string `json:"filter_hash"`
+	// Statuses maps a document ID to "success", "error", or "skipped",
+	// letting a resumed run retry just the error rows (see IDsWithStatus)
+	// instead of only ever skipping everything already seen.
+	Statuses map[int]string `json:"statuses"`
+	// ProcessedIDs is every key of Statuses, kept as its own field for
+	// callers that just want "already seen" without looking at status.
+	ProcessedIDs []int `json:"processed_ids"`
+	Success      int   `json:"success"`
+	Errors       int   `json:"errors"`
+	Skipped      int   `json:"skipped"`
+}
+
+// IDsWithStatus returns every document ID recorded with the given status
+// ("success", "error", or "skipped").
+func (cp Checkpoint) IDsWithStatus(status string) []int {
+	var ids []int
+	for id, s := range cp.Statuses {
+		if s == status {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// HashFilterParams derives a short, stable identifier for the filter
+// parameters a run used (e.g. regex patterns, semantic threshold), so a
+// checkpoint written under one configuration isn't silently applied to a
+// resumed run with different filters. It is not a security hash - a
+// collision only causes a confirmable warning, not data loss.
+func HashFilterParams(params ...interface{}) string {
+	h := fnv.New64a()
+	fmt.Fprint(h, params...)
+	return fmt.Sprintf("%x", h.Sum64())
+}
+
+// SaveCheckpoint writes cp as indented JSON to path, creating any missing
+// parent directory and the file itself.
+func SaveCheckpoint(path string, cp Checkpoint) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create checkpoint directory: %w", err)
+		}
+	}
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write checkpoint file: %w", err)
+	}
+	return nil
+}
+
+// LoadCheckpoint reads and parses the checkpoint file at path.
+func LoadCheckpoint(path string) (Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Checkpoint{}, fmt.Errorf("failed to read checkpoint file: %w", err)
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return Checkpoint{}, fmt.Errorf("failed to parse checkpoint file: %w", err)
+	}
+	return cp, nil
+}
+
+// DefaultCheckpointDir is where a run's checkpoint lands when main.go isn't
+// told an explicit --resume path: ~/.paperless-ngx-privatemode-ai/runs.
+func DefaultCheckpointDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve home directory: %w", err)
+	}
+	return filepath.Join(home, ".paperless-ngx-privatemode-ai", "runs"), nil
+}
+
+// NewCheckpointPath builds a fresh checkpoint file path for action under
+// dir, e.g. dir/"title-1700000000.json", so concurrent or repeated runs of
+// the same action don't clobber each other's in-flight checkpoint.
+func NewCheckpointPath(dir, action string) string {
+	return filepath.Join(dir, fmt.Sprintf("%s-%d.json", sanitizeForFilename(action), time.Now().Unix()))
+}
+
+// FindLatestCheckpoint scans dir for the most recently modified checkpoint
+// file whose Action field equals action, for main.go to offer resuming an
+// interrupted run without the user having to remember --resume's path. ok
+// is false if dir doesn't exist or nothing matches.
+func FindLatestCheckpoint(dir, action string) (path string, cp Checkpoint, ok bool, err error) {
+	entries, readErr := os.ReadDir(dir)
+	if readErr != nil {
+		if os.IsNotExist(readErr) {
+			return "", Checkpoint{}, false, nil
+		}
+		return "", Checkpoint{}, false, fmt.Errorf("failed to read checkpoint directory: %w", readErr)
+	}
+
+	var latestPath string
+	var latestModTime time.Time
+	var latestCp Checkpoint
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		candidate := filepath.Join(dir, entry.Name())
+		loaded, loadErr := LoadCheckpoint(candidate)
+		if loadErr != nil || loaded.Action != action {
+			continue
+		}
+		info, statErr := entry.Info()
+		if statErr != nil {
+			continue
+		}
+		if latestPath == "" || info.ModTime().After(latestModTime) {
+			latestPath = candidate
+			latestModTime = info.ModTime()
+			latestCp = loaded
+		}
+	}
+
+	return latestPath, latestCp, latestPath != "", nil
+}
+
+// sanitizeForFilename replaces anything but alphanumerics with "-", so an
+// action's free-text Description() can be used as a filename prefix.
+func sanitizeForFilename(s string) string {
+	var b strings.Builder
+	lastWasDash := true // trims a leading dash for free
+	for _, r := range s {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			lastWasDash = false
+		} else if !lastWasDash {
+			b.WriteRune('-')
+			lastWasDash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
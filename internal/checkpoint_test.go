@@ -0,0 +1,150 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// touchLater bumps path's mtime forward so FindLatestCheckpoint's
+// "most recently modified" comparison doesn't depend on how fast two
+// SaveCheckpoint calls in the same test ran.
+func touchLater(t *testing.T, path string) {
+	t.Helper()
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+}
+
+func TestSaveLoadCheckpoint_RoundTrips(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "checkpoint.json")
+	cp := Checkpoint{
+		Action:       "title generation",
+		FilterHash:   "abc123",
+		Statuses:     map[int]string{1: "success", 2: "error", 3: "skipped"},
+		ProcessedIDs: []int{1, 2, 3},
+		Success:      1,
+		Errors:       1,
+		Skipped:      1,
+	}
+
+	if err := SaveCheckpoint(path, cp); err != nil {
+		t.Fatalf("SaveCheckpoint: %v", err)
+	}
+
+	loaded, err := LoadCheckpoint(path)
+	if err != nil {
+		t.Fatalf("LoadCheckpoint: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, cp) {
+		t.Errorf("loaded checkpoint = %+v, want %+v", loaded, cp)
+	}
+}
+
+func TestLoadCheckpoint_MissingFile(t *testing.T) {
+	if _, err := LoadCheckpoint(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected an error loading a nonexistent checkpoint file")
+	}
+}
+
+func TestCheckpoint_IDsWithStatus(t *testing.T) {
+	cp := Checkpoint{Statuses: map[int]string{3: "success", 1: "success", 2: "error"}}
+
+	if got := cp.IDsWithStatus("success"); !reflect.DeepEqual(got, []int{1, 3}) {
+		t.Errorf("IDsWithStatus(success) = %v, want [1 3]", got)
+	}
+	if got := cp.IDsWithStatus("error"); !reflect.DeepEqual(got, []int{2}) {
+		t.Errorf("IDsWithStatus(error) = %v, want [2]", got)
+	}
+	if got := cp.IDsWithStatus("skipped"); len(got) != 0 {
+		t.Errorf("IDsWithStatus(skipped) = %v, want empty", got)
+	}
+}
+
+func TestHashFilterParams_StableAndSensitiveToInputs(t *testing.T) {
+	base := HashFilterParams("title-pattern", "content-pattern", 0.75)
+	if again := HashFilterParams("title-pattern", "content-pattern", 0.75); again != base {
+		t.Errorf("HashFilterParams is not deterministic: %q != %q", again, base)
+	}
+	if diff := HashFilterParams("other-pattern", "content-pattern", 0.75); diff == base {
+		t.Error("HashFilterParams ignored a changed parameter")
+	}
+}
+
+func TestSanitizeForFilename(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"alphanumeric only", "titlegeneration", "titlegeneration"},
+		{"spaces become dashes", "title generation", "title-generation"},
+		{"leading punctuation trimmed", "!!!rules", "rules"},
+		{"trailing punctuation trimmed", "ocr???", "ocr"},
+		{"runs collapse to one dash", "a---b   c", "a-b-c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeForFilename(tt.in); got != tt.want {
+				t.Errorf("sanitizeForFilename(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindLatestCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+
+	older := filepath.Join(dir, "title-100.json")
+	if err := SaveCheckpoint(older, Checkpoint{Action: "title"}); err != nil {
+		t.Fatalf("SaveCheckpoint(older): %v", err)
+	}
+	newer := filepath.Join(dir, "title-200.json")
+	if err := SaveCheckpoint(newer, Checkpoint{Action: "title", FilterHash: "newest"}); err != nil {
+		t.Fatalf("SaveCheckpoint(newer): %v", err)
+	}
+	// Give the newer file a later mtime regardless of how fast the two
+	// SaveCheckpoint calls above ran.
+	touchLater(t, newer)
+
+	other := filepath.Join(dir, "rules-1.json")
+	if err := SaveCheckpoint(other, Checkpoint{Action: "rules"}); err != nil {
+		t.Fatalf("SaveCheckpoint(other): %v", err)
+	}
+
+	path, cp, ok, err := FindLatestCheckpoint(dir, "title")
+	if err != nil {
+		t.Fatalf("FindLatestCheckpoint: %v", err)
+	}
+	if !ok || path != newer || cp.FilterHash != "newest" {
+		t.Errorf("FindLatestCheckpoint = path=%q cp=%+v ok=%v, want the newer \"title\" checkpoint", path, cp, ok)
+	}
+}
+
+func TestFindLatestCheckpoint_NoMatchingAction(t *testing.T) {
+	dir := t.TempDir()
+	if err := SaveCheckpoint(filepath.Join(dir, "rules-1.json"), Checkpoint{Action: "rules"}); err != nil {
+		t.Fatalf("SaveCheckpoint: %v", err)
+	}
+
+	_, _, ok, err := FindLatestCheckpoint(dir, "title")
+	if err != nil {
+		t.Fatalf("FindLatestCheckpoint: %v", err)
+	}
+	if ok {
+		t.Error("expected ok=false when no checkpoint matches the requested action")
+	}
+}
+
+func TestFindLatestCheckpoint_MissingDir(t *testing.T) {
+	_, _, ok, err := FindLatestCheckpoint(filepath.Join(t.TempDir(), "does-not-exist"), "title")
+	if err != nil {
+		t.Fatalf("FindLatestCheckpoint on a missing dir should report ok=false, not an error: %v", err)
+	}
+	if ok {
+		t.Error("expected ok=false for a missing directory")
+	}
+}
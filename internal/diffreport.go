@@ -0,0 +1,39 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// DiffEntry is one pending title or content change recorded by
+// processor.ActionExecutor, whether or not it was actually written to
+// Paperless (see ActionExecutor.SetDryRun). main.go writes the full set to
+// a ".diff.jsonl" report file next to the config when --dry-run is set.
+type DiffEntry struct {
+	ID     int     `json:"id"`
+	URL    string  `json:"url"`
+	Field  string  `json:"field"`
+	Old    string  `json:"old"`
+	New    string  `json:"new"`
+	Score  float64 `json:"score"`
+	Reason string  `json:"reason"`
+}
+
+// SaveDiffReport writes entries to path as JSON Lines (one DiffEntry per
+// line) rather than a single JSON array, so a future ApplyPlanAction can
+// replay just the approved rows without parsing the whole file into memory.
+func SaveDiffReport(path string, entries []DiffEntry) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, entry := range entries {
+		if err := enc.Encode(entry); err != nil {
+			return fmt.Errorf("failed to marshal diff report entry for document %d: %w", entry.ID, err)
+		}
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write diff report file: %w", err)
+	}
+	return nil
+}
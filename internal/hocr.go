@@ -0,0 +1,45 @@
+package internal
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// WriteHOCR writes a sidecar .hocr file for documentID's OCR pages under
+// dir, returning the path written. Each page becomes one "ocr_page" div
+// containing a single "ocr_par" span holding that page's full text: the LLM
+// OCR backend returns free text with no word-level bounding boxes, so this
+// is a layout-free approximation of real hOCR output (which normally
+// carries a bbox per word from the OCR engine), good enough for tools that
+// just want the text back out page by page.
+func WriteHOCR(dir string, documentID int, pages []string) (string, error) {
+	if dir == "" {
+		return "", fmt.Errorf("hocr_dir is not configured")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create hocr directory: %w", err)
+	}
+
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	b.WriteString("<meta name='ocr-system' content='paperless-ngx-privatemode-ai'>\n")
+	b.WriteString("<meta name='ocr-capabilities' content='ocr_page ocr_par'>\n")
+	b.WriteString("</head>\n<body>\n")
+	for i, text := range pages {
+		pageID := "page_" + strconv.Itoa(i+1)
+		b.WriteString(fmt.Sprintf("<div class='ocr_page' id='%s'>\n", pageID))
+		b.WriteString(fmt.Sprintf("<p class='ocr_par'>%s</p>\n", html.EscapeString(text)))
+		b.WriteString("</div>\n")
+	}
+	b.WriteString("</body>\n</html>\n")
+
+	path := filepath.Join(dir, strconv.Itoa(documentID)+".hocr")
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return "", fmt.Errorf("failed to write hocr sidecar: %w", err)
+	}
+	return path, nil
+}
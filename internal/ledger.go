@@ -0,0 +1,119 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Usage mirrors the OpenAI chat-completions "usage" block returned
+// alongside a response.
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// ErrBudgetExceeded is returned by MakeOcr/GenerateTitleFromContent when
+// config.Processing.Budgets would be exceeded by processing documentID.
+type ErrBudgetExceeded struct {
+	DocumentID int
+	Reason     string
+}
+
+func (e *ErrBudgetExceeded) Error() string {
+	return fmt.Sprintf("token budget exceeded for document %d: %s", e.DocumentID, e.Reason)
+}
+
+type tokenLedgerEntry struct {
+	Time       time.Time `json:"time"`
+	DocumentID int       `json:"document_id"`
+	Model      string    `json:"model"`
+	Usage      Usage     `json:"usage"`
+}
+
+// TokenLedger aggregates LLM token consumption per document and per day,
+// and appends each recorded usage as a line of JSON to flushPath (when
+// set) so spend can be audited after the fact.
+type TokenLedger struct {
+	mu          sync.Mutex
+	perDocument map[int]int
+	perDay      map[string]int
+	flushPath   string
+}
+
+func NewTokenLedger(flushPath string) *TokenLedger {
+	return &TokenLedger{
+		perDocument: make(map[int]int),
+		perDay:      make(map[string]int),
+		flushPath:   flushPath,
+	}
+}
+
+// Record adds usage to the running totals for documentID and for today, and
+// appends the entry to the ledger file if one is configured.
+func (l *TokenLedger) Record(documentID int, model string, usage Usage) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.perDocument[documentID] += usage.TotalTokens
+	l.perDay[today()] += usage.TotalTokens
+
+	l.appendToFile(tokenLedgerEntry{Time: time.Now(), DocumentID: documentID, Model: model, Usage: usage})
+}
+
+func (l *TokenLedger) appendToFile(entry tokenLedgerEntry) {
+	if l.flushPath == "" {
+		return
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	f, err := os.OpenFile(l.flushPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	f.Write(append(data, '\n'))
+}
+
+// TokensForDocument returns the tokens recorded for documentID so far.
+func (l *TokenLedger) TokensForDocument(documentID int) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.perDocument[documentID]
+}
+
+// TokensToday returns the tokens recorded across all documents today.
+func (l *TokenLedger) TokensToday() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.perDay[today()]
+}
+
+func today() string {
+	return time.Now().Format("2006-01-02")
+}
+
+// checkBudget returns an *ErrBudgetExceeded if processing documentID would
+// violate config.Processing.Budgets.
+func (c *LLMClient) checkBudget(documentID int) error {
+	budgets := c.config.Processing.Budgets
+
+	if budgets.MaxTokensPerDocument > 0 {
+		if used := c.ledger.TokensForDocument(documentID); used >= budgets.MaxTokensPerDocument {
+			return &ErrBudgetExceeded{DocumentID: documentID, Reason: fmt.Sprintf("document has used %d of %d max tokens", used, budgets.MaxTokensPerDocument)}
+		}
+	}
+
+	if budgets.MaxTokensPerDay > 0 {
+		if used := c.ledger.TokensToday(); used >= budgets.MaxTokensPerDay {
+			return &ErrBudgetExceeded{DocumentID: documentID, Reason: fmt.Sprintf("today has used %d of %d max tokens", used, budgets.MaxTokensPerDay)}
+		}
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package internal
 
 import (
 	"bytes"
+	"context"
 	_ "embed"
 	"encoding/base64"
 	"encoding/json"
@@ -12,14 +13,69 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/dhcgn/paperless-ngx-privatemode-ai/config"
+	"github.com/dhcgn/paperless-ngx-privatemode-ai/internal/cache"
 )
 
 //go:embed llm_assets/schema_title_generation.json
 var schema_title_generation []byte
 
+// Built-in JSON schemas for MakeOcrStructured, covering the paperless-ngx
+// document types that come up often enough to ship by default. Users can
+// register additional schemas for their own document types via
+// config.Processing.OCR.Schemas.
+//
+//go:embed llm_assets/schema_invoice.json
+var schema_invoice []byte
+
+//go:embed llm_assets/schema_receipt.json
+var schema_receipt []byte
+
+//go:embed llm_assets/schema_contract.json
+var schema_contract []byte
+
+//go:embed llm_assets/schema_letter.json
+var schema_letter []byte
+
+//go:embed llm_assets/schema_metadata.json
+var schema_metadata []byte
+
+// Base schema for ClassifyDocument, completed at call time with the
+// enumerated tag/correspondent/document type IDs of the Taxonomy passed in
+// (see buildClassificationSchema).
+//
+//go:embed llm_assets/schema_classification.json
+var schema_classification []byte
+
+// BuiltinOCRSchemas maps a schema name to its embedded JSON schema document,
+// in the {"name", "schema", "strict"} shape expected by sendStructuredChatRequest.
+var BuiltinOCRSchemas = map[string][]byte{
+	"invoice":  schema_invoice,
+	"receipt":  schema_receipt,
+	"contract": schema_contract,
+	"letter":   schema_letter,
+}
+
+// ResolveOCRSchema looks up name in BuiltinOCRSchemas first, then in
+// config.Processing.OCR.Schemas (a name -> file path map for user-registered
+// schemas).
+func ResolveOCRSchema(cfg *config.Config, name string) ([]byte, error) {
+	if schema, ok := BuiltinOCRSchemas[name]; ok {
+		return schema, nil
+	}
+	if path, ok := cfg.Processing.OCR.Schemas[name]; ok {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read schema %q from %q: %w", name, path, err)
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("unknown OCR schema %q", name)
+}
+
 // Vision types for OCR chat request
 type MessageContent struct {
 	Type     string    `json:"type"`
@@ -44,6 +100,16 @@ type VisionChatRequest struct {
 type LLMClient struct {
 	config     *config.Config
 	httpClient *http.Client
+	backend    Backend
+	ledger     *TokenLedger
+	breaker    *circuitBreaker
+	limiter    *rateLimiter
+	cache      cache.Store
+	// deadlineMu guards deadlineCh and deadlineTimer, armed by SetDeadline
+	// and consumed by withDeadline.
+	deadlineMu    sync.Mutex
+	deadlineCh    chan struct{}
+	deadlineTimer *time.Timer
 }
 
 type ChatMessage struct {
@@ -82,31 +148,78 @@ type ChatChoice struct {
 	Message ChatMessage `json:"message"`
 }
 
-// doRequestWithRetry wraps httpClient.Do(req) with retry logic (3 attempts)
+const (
+	backoffBase = 200 * time.Millisecond
+	backoffCap  = 10 * time.Second
+)
+
+// doRequestWithRetry wraps httpClient.Do(req) with a per-host circuit
+// breaker, a token-bucket rate limiter, and full-jitter exponential backoff.
+// It retries on network errors and on the HTTP status codes a caller can
+// reasonably expect to succeed on a later attempt (408/425/429/5xx),
+// honoring a Retry-After header when the server sends one.
 func (c *LLMClient) doRequestWithRetry(req *http.Request) (*http.Response, error) {
-	var resp *http.Response
-	var err error
-	maxRetries := 3
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		resp, err = c.httpClient.Do(req)
-		if err == nil {
-			return resp, nil
-		}
+	if !c.breaker.allow() {
+		return nil, fmt.Errorf("circuit breaker is %s for %s", c.breaker, req.URL.Host)
+	}
 
-		// If we got a response, drain the body to avoid resource leaks
-		io.Copy(io.Discard, resp.Body)
-		resp.Body.Close()
+	maxRetries := c.config.LLM.API.Resilience.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = 3
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		c.limiter.wait()
+
+		// req's body (if any) is a one-shot stream already drained by the
+		// previous attempt's Do(), so retries must rebuild it from
+		// req.GetBody rather than replaying the same *http.Request.
+		attemptReq := req
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				lastErr = err
+				break
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
 
-		if attempt < maxRetries {
-			time.Sleep(time.Duration(100*attempt) * time.Millisecond)
+		resp, err := c.httpClient.Do(attemptReq)
+		if err != nil {
+			lastErr = err
+			c.breaker.recordFailure()
+		} else if isRetryableStatus(resp.StatusCode) {
+			lastErr = fmt.Errorf("retryable status code: %d", resp.StatusCode)
+			delay, ok := retryAfterDelay(resp.Header.Get("Retry-After"))
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			c.breaker.recordFailure()
+
+			if attempt == maxRetries-1 {
+				break
+			}
+			if !ok {
+				delay = backoffDelay(attempt, backoffBase, backoffCap)
+			}
+			time.Sleep(delay)
 			continue
+		} else {
+			c.breaker.recordSuccess()
+			return resp, nil
+		}
+
+		if attempt < maxRetries-1 {
+			time.Sleep(backoffDelay(attempt, backoffBase, backoffCap))
 		}
 	}
-	return nil, fmt.Errorf("failed to connect after %d attempts: %w", maxRetries, err)
+	return nil, fmt.Errorf("failed to connect after %d attempts: %w", maxRetries, lastErr)
 }
 
 type ChatResponse struct {
 	Choices []ChatChoice `json:"choices"`
+	Usage   Usage        `json:"usage"`
 }
 
 type ModelInfo struct {
@@ -146,17 +259,627 @@ func newHTTPClient(timeoutSec int) *http.Client {
 
 func NewLLMClient(config *config.Config) *LLMClient {
 	timeout := config.LLM.API.Timeout
-	return &LLMClient{
+	resilience := config.LLM.API.Resilience
+	c := &LLMClient{
 		config:     config,
 		httpClient: newHTTPClient(timeout),
+		ledger:     NewTokenLedger(config.Processing.Budgets.LedgerPath),
+		breaker:    newCircuitBreaker(resilience.CircuitBreaker.FailureThreshold, resilience.CircuitBreaker.CooldownSeconds),
+		limiter:    newRateLimiter(resilience.RateLimit.RequestsPerMinute),
+		cache:      newResultCache(config),
+	}
+
+	backend, err := newBackend(c)
+	if err != nil {
+		// Fall back to the OpenAI-compatible backend so a typo in
+		// llm.api.provider doesn't prevent the client from being usable;
+		// CheckConnection will still surface connectivity problems.
+		backend = &openAIBackend{client: c}
+	}
+	c.backend = backend
+
+	return c
+}
+
+// newResultCache builds the Store configured by config.Processing.Cache,
+// returning nil (caching disabled) when the config opts out or the store
+// fails to open. A broken cache shouldn't prevent the client from being
+// usable; callers just pay for every request instead of reusing a cached
+// one.
+func newResultCache(config *config.Config) cache.Store {
+	if config.Processing.Cache.Disabled {
+		return nil
+	}
+	store, err := cache.New(config.Processing.Cache)
+	if err != nil {
+		return nil
+	}
+	return store
+}
+
+// SetDeadline arms a one-shot deadline for every request made through c
+// from now on, mirroring the split read/write cancel-channel pattern used
+// by netstack's gonet adapter: a timer closes deadlineCh when t arrives, and
+// withDeadline derives a per-call context that ends early if it does.
+// Calling it again replaces the previous deadline; a zero t disarms it.
+func (c *LLMClient) SetDeadline(t time.Time) {
+	c.deadlineMu.Lock()
+	defer c.deadlineMu.Unlock()
+	if c.deadlineTimer != nil {
+		c.deadlineTimer.Stop()
+	}
+	if t.IsZero() {
+		c.deadlineCh = nil
+		c.deadlineTimer = nil
+		return
+	}
+	ch := make(chan struct{})
+	c.deadlineCh = ch
+	c.deadlineTimer = time.AfterFunc(time.Until(t), func() { close(ch) })
+}
+
+// withDeadline derives a context from ctx that also ends when a timer armed
+// by SetDeadline fires, for request methods to pass to
+// http.NewRequestWithContext. The caller must invoke the returned cancel
+// once the request completes to release the goroutine watching deadlineCh.
+func (c *LLMClient) withDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	c.deadlineMu.Lock()
+	ch := c.deadlineCh
+	c.deadlineMu.Unlock()
+	if ch == nil {
+		return ctx, func() {}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-ch:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// CheckConnection delegates to the configured Backend.
+func (c *LLMClient) CheckConnection(ctx context.Context) error {
+	if err := c.backend.CheckConnection(ctx); err != nil {
+		return fmt.Errorf("%w (circuit breaker: %s)", err, c.breaker)
+	}
+	return nil
+}
+
+// BreakerState reports the current state of the per-host circuit breaker
+// ("closed", "open", or "half-open").
+func (c *LLMClient) BreakerState() string {
+	return c.breaker.String()
+}
+
+// TokensForDocument reports the tokens recorded for documentID so far. It
+// lets a caller take a before/after reading around an uninstrumented call
+// (e.g. a single OCR page) to get a best-effort per-call token count, since
+// Backend.OCR itself doesn't return a Usage block to its caller.
+func (c *LLMClient) TokensForDocument(documentID int) int {
+	return c.ledger.TokensForDocument(documentID)
+}
+
+// GenerateTitleFromContent checks documentID's token budget and, if it
+// still has headroom, delegates to the configured Backend. Results are
+// cached by a hash of (content, model, prompt, truncateChars), so re-running
+// the same document again is instant and free. ctx is checked before the
+// (uncached) backend call so a cancelled batch run (see main.go's
+// signal.NotifyContext wiring) doesn't start title generation for documents
+// it hasn't gotten to yet.
+//
+// There is no streaming counterpart of this or MakeOcr: an SSE-based
+// MakeOcrStream/GenerateTitleStream was prototyped once, but every call into
+// LLMClient is now ctx-aware (see withDeadline and the cancellation checks
+// above), and nothing in this codebase consumes a partial OCR/title result
+// before the request finishes anyway - processor's stageDashboard only
+// reports in-flight counts per stage, not per-token progress. Descoped
+// rather than carried as unreachable code.
+func (c *LLMClient) GenerateTitleFromContent(ctx context.Context, documentID int, content string) (CaptionResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return CaptionResponse{}, err
+	}
+	if err := c.checkBudget(documentID); err != nil {
+		return CaptionResponse{}, err
+	}
+
+	key := cache.KeyForTitle(content, c.config.LLM.Models.TitleGeneration, c.config.LLM.Prompts.TitleGeneration, c.config.Processing.TitleGeneration.TruncateCharactersOfContent)
+	if cached, ok := c.getCached(key); ok {
+		var resp CaptionResponse
+		if err := json.Unmarshal(cached, &resp); err == nil {
+			return resp, nil
+		}
+	}
+
+	resp, err := c.backend.GenerateTitle(ctx, documentID, content)
+	if err != nil {
+		return resp, err
+	}
+	c.setCached(key, resp)
+	return resp, nil
+}
+
+// MakeOcr checks documentID's token budget and, if it still has headroom,
+// delegates to the configured Backend. Results are cached by a hash of
+// (pdfBytes, page, model, prompt), so re-running the same document and page
+// again is instant and free. ctx is forwarded to OCRImage so a cancelled
+// batch run (see main.go's signal.NotifyContext wiring) skips any page not
+// already cached.
+func (c *LLMClient) MakeOcr(ctx context.Context, documentID int, pdfBytes []byte, page int, imageData []byte) (string, error) {
+	key := cache.KeyForOCR(pdfBytes, page, c.config.LLM.Models.OCR, c.config.LLM.Prompts.OCR)
+	if cached, ok := c.getCached(key); ok {
+		return string(cached), nil
+	}
+
+	content, err := c.OCRImage(ctx, documentID, imageData)
+	if err != nil {
+		return "", err
+	}
+	if c.cache != nil {
+		c.cache.Set(key, []byte(content))
+	}
+	return content, nil
+}
+
+// OCRImage checks documentID's token budget and delegates to the configured
+// Backend directly, bypassing MakeOcr's pdfBytes/page result cache. It
+// exists for callers that render more than one candidate image per page
+// (processor.OcrPipeline's binarization-threshold trials) and so can't key
+// a cache entry on page alone.
+func (c *LLMClient) OCRImage(ctx context.Context, documentID int, imageData []byte) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if len(imageData) < 2 || (imageData[0] != 0xFF || imageData[1] != 0xD8) {
+		return "", fmt.Errorf("invalid image data: not a valid JPEG")
+	}
+	if err := c.checkBudget(documentID); err != nil {
+		return "", err
+	}
+	return c.backend.OCR(ctx, documentID, imageData)
+}
+
+// getCached looks key up in the result cache, if one is configured.
+func (c *LLMClient) getCached(key string) ([]byte, bool) {
+	if c.cache == nil {
+		return nil, false
+	}
+	value, ok, err := c.cache.Get(key)
+	if err != nil || !ok {
+		return nil, false
+	}
+	return value, true
+}
+
+// setCached marshals value as JSON and stores it under key, if a result
+// cache is configured. value must be JSON-marshalable; errors are ignored,
+// same as other best-effort cache writes in this client.
+func (c *LLMClient) setCached(key string, value interface{}) {
+	if c.cache == nil {
+		return
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	c.cache.Set(key, data)
+}
+
+// MakeOcrStructured extracts fields from a document image directly into the
+// shape described by schemaDoc (one of BuiltinOCRSchemas or a schema
+// resolved via ResolveOCRSchema), instead of free-text OCR. It combines the
+// vision message construction of sendOCRRequest with the
+// response_format: json_schema block of sendStructuredChatRequest. Not all
+// vision endpoints support response_format on chat completions, so on a
+// non-2xx response the request is retried once without it, and the reply is
+// best-effort parsed by extracting the first fenced ```json``` block.
+func (c *LLMClient) MakeOcrStructured(ctx context.Context, documentID int, imageData []byte, schemaDoc []byte) (json.RawMessage, error) {
+	if len(imageData) < 2 || (imageData[0] != 0xFF || imageData[1] != 0xD8) {
+		return nil, fmt.Errorf("invalid image data: not a valid JPEG")
+	}
+	if err := c.checkBudget(documentID); err != nil {
+		return nil, err
+	}
+
+	var schema interface{}
+	if err := json.Unmarshal(schemaDoc, &schema); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	schemaMap, ok := schema.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid schema format")
+	}
+	name, ok := schemaMap["name"].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid schema format: %q must be a string", "name")
+	}
+	strict, ok := schemaMap["strict"].(bool)
+	if !ok {
+		return nil, fmt.Errorf("invalid schema format: %q must be a bool", "strict")
+	}
+
+	response, usage, err := c.sendStructuredVisionRequest(ctx, c.config.LLM.Models.OCR, c.config.LLM.Prompts.OCR, imageData, &ResponseFormat{
+		Type: "json_schema",
+		JSONSchema: JSONSchema{
+			Name:   name,
+			Schema: schemaMap["schema"],
+			Strict: strict,
+		},
+	})
+	if err != nil {
+		// Some vision endpoints reject response_format on chat completions;
+		// fall back to an unconstrained request and best-effort parse.
+		response, usage, err = c.sendStructuredVisionRequest(ctx, c.config.LLM.Models.OCR, c.config.LLM.Prompts.OCR, imageData, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract structured content: %w", err)
+		}
+		response = extractFencedJSON(response)
+	}
+	c.ledger.Record(documentID, c.config.LLM.Models.OCR, usage)
+
+	if !json.Valid([]byte(response)) {
+		return nil, fmt.Errorf("model response is not valid JSON: %s", response)
+	}
+	return json.RawMessage(response), nil
+}
+
+// ExtractFields is the structured-metadata counterpart to MakeOcrStructured:
+// instead of returning a caller-defined document type's shape, it extracts
+// the field set ExtractMetadataAction maps onto Paperless correspondents,
+// document types, and custom fields. schemaDoc defaults to the built-in
+// metadata_extraction schema (schema_metadata.json) when nil, so callers
+// with their own field set can still supply one of BuiltinOCRSchemas or a
+// schema resolved via ResolveOCRSchema. The reply is validated against
+// schemaDoc's required properties and, on a mismatch, the request is
+// retried once before giving up.
+func (c *LLMClient) ExtractFields(ctx context.Context, documentID int, imageData []byte, schemaDoc []byte) (map[string]interface{}, error) {
+	if schemaDoc == nil {
+		schemaDoc = schema_metadata
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < 2; attempt++ {
+		raw, err := c.MakeOcrStructured(ctx, documentID, imageData, schemaDoc)
+		if err != nil {
+			return nil, err
+		}
+
+		fields, err := validateAgainstSchema(raw, schemaDoc)
+		if err == nil {
+			return fields, nil
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("model reply did not match schema after retrying: %w", lastErr)
+}
+
+// validateAgainstSchema parses raw as JSON and checks that every property
+// schemaDoc marks required is present, mirroring the "required" array
+// already enforced server-side by "strict": true.
+func validateAgainstSchema(raw json.RawMessage, schemaDoc []byte) (map[string]interface{}, error) {
+	var wrapper struct {
+		Schema struct {
+			Required []string `json:"required"`
+		} `json:"schema"`
+	}
+	if err := json.Unmarshal(schemaDoc, &wrapper); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, fmt.Errorf("model reply is not a JSON object: %w", err)
+	}
+
+	for _, name := range wrapper.Schema.Required {
+		if _, ok := fields[name]; !ok {
+			return nil, fmt.Errorf("missing required field %q in model reply: %s", name, raw)
+		}
+	}
+	return fields, nil
+}
+
+// Classification is LLMClient.ClassifyDocument's result: the
+// tags/correspondent/document type it picked out of the Taxonomy passed in,
+// plus a confidence score the caller can gate writes on (see
+// config.Processing.Classification.Threshold).
+type Classification struct {
+	TagIDs          []int   `json:"tag_ids"`
+	CorrespondentID int     `json:"correspondent_id"`
+	DocumentTypeID  int     `json:"document_type_id"`
+	Confidence      float64 `json:"confidence"`
+	Rationale       string  `json:"rationale"`
+}
+
+// ClassifyDocument is the routing counterpart to ExtractFields: instead of
+// free-form fields, it picks tags/correspondent/document type out of
+// taxonomy (fetched via PaperlessClient.GetTags/GetCorrespondents/
+// GetDocumentTypes) via the same sendStructuredChatRequest machinery
+// GenerateTitleFromContent uses. It takes no documentID, the same as Embed,
+// since a classification isn't naturally document-scoped the way OCR or
+// title generation are; usage is recorded against document 0.
+func (c *LLMClient) ClassifyDocument(ctx context.Context, content string, taxonomy Taxonomy) (Classification, error) {
+	if err := ctx.Err(); err != nil {
+		return Classification{}, err
+	}
+
+	schemaDoc, err := buildClassificationSchema(taxonomy)
+	if err != nil {
+		return Classification{}, fmt.Errorf("failed to build classification schema: %w", err)
+	}
+
+	prompt := classificationPrompt(content, taxonomy)
+
+	response, usage, err := c.sendStructuredChatRequest(ctx, c.config.LLM.Models.TitleGeneration, prompt, schemaDoc)
+	if err != nil {
+		return Classification{}, fmt.Errorf("failed to classify document: %w", err)
+	}
+	c.ledger.Record(0, c.config.LLM.Models.TitleGeneration, usage)
+
+	var result Classification
+	if err := json.Unmarshal([]byte(response), &result); err != nil {
+		return Classification{}, fmt.Errorf("model reply is not valid JSON: %w", err)
+	}
+	return result, nil
+}
+
+// buildClassificationSchema completes schema_classification.json with the
+// IDs of taxonomy's tags/correspondents/document types, so the model can
+// only pick from resources that actually exist in Paperless. 0 is always
+// allowed for correspondent_id/document_type_id, meaning "none of these
+// fit".
+func buildClassificationSchema(taxonomy Taxonomy) ([]byte, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(schema_classification, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+
+	properties := doc["schema"].(map[string]interface{})["properties"].(map[string]interface{})
+	properties["tag_ids"].(map[string]interface{})["items"].(map[string]interface{})["enum"] = taxonomyIDs(taxonomy.Tags)
+	properties["correspondent_id"].(map[string]interface{})["enum"] = append([]int{0}, taxonomyIDs(taxonomy.Correspondents)...)
+	properties["document_type_id"].(map[string]interface{})["enum"] = append([]int{0}, taxonomyIDs(taxonomy.DocumentTypes)...)
+
+	return json.Marshal(doc)
+}
+
+// taxonomyIDs returns the IDs of items, in order.
+func taxonomyIDs(items []TaxonomyItem) []int {
+	ids := make([]int, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	return ids
+}
+
+// classificationPrompt renders content and taxonomy into the free-text
+// instruction sendStructuredChatRequest sends alongside schema_classification.json's
+// response_format, listing every tag/correspondent/document type by
+// "id: name" so the model can refer to them by ID.
+func classificationPrompt(content string, taxonomy Taxonomy) string {
+	return fmt.Sprintf(
+		"Classify the following document. Pick every tag that applies from the list below, "+
+			"and the single best-fitting correspondent and document type (or 0 if none fit). "+
+			"Report your confidence in this classification from 0 to 1.\n\n"+
+			"Tags:\n%s\n\nCorrespondents:\n%s\n\nDocument types:\n%s\n\nDocument content:\n%s",
+		taxonomyListing(taxonomy.Tags), taxonomyListing(taxonomy.Correspondents), taxonomyListing(taxonomy.DocumentTypes), content,
+	)
+}
+
+// taxonomyListing renders items as one "id: name" line each, for
+// classificationPrompt.
+func taxonomyListing(items []TaxonomyItem) string {
+	if len(items) == 0 {
+		return "(none defined)"
+	}
+	lines := make([]string, len(items))
+	for i, item := range items {
+		lines[i] = fmt.Sprintf("%d: %s", item.ID, item.Name)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// extractFencedJSON returns the contents of the first ```json fenced code
+// block in s, or s unchanged if no such block is found.
+func extractFencedJSON(s string) string {
+	const fence = "```"
+	start := strings.Index(s, fence+"json")
+	if start == -1 {
+		start = strings.Index(s, fence)
+		if start == -1 {
+			return s
+		}
+		start += len(fence)
+	} else {
+		start += len(fence + "json")
+	}
+	end := strings.Index(s[start:], fence)
+	if end == -1 {
+		return s
+	}
+	return strings.TrimSpace(s[start : start+end])
+}
+
+// sendStructuredVisionRequest is the vision-endpoint analogue of
+// sendStructuredChatRequest: it builds the same image+text message as
+// sendOCRRequest, but optionally attaches responseFormat so the reply is
+// constrained to a JSON schema instead of free text.
+func (c *LLMClient) sendStructuredVisionRequest(ctx context.Context, model, prompt string, imageData []byte, responseFormat *ResponseFormat) (string, Usage, error) {
+	url := strings.TrimSuffix(c.config.LLM.API.BaseURL, "/") + c.config.LLM.API.Endpoint
+
+	dataURL := "data:;base64," + base64.StdEncoding.EncodeToString(imageData)
+
+	chatReq := struct {
+		Model          string              `json:"model"`
+		Messages       []VisionChatMessage `json:"messages"`
+		ResponseFormat *ResponseFormat     `json:"response_format,omitempty"`
+	}{
+		Model: model,
+		Messages: []VisionChatMessage{
+			{
+				Role: "user",
+				Content: []MessageContent{
+					{Type: "text", Text: prompt},
+					{Type: "image_url", ImageURL: &ImageURL{URL: dataURL}},
+				},
+			},
+		},
+		ResponseFormat: responseFormat,
+	}
+
+	reqBody, err := json.Marshal(chatReq)
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	ctx, cancel := c.withDeadline(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(reqBody))
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if c.config.LLM.API.Debug && c.config.LLM.API.DebugFolder != "" {
+		if err := saveDebugScript(url, reqBody, c.config.LLM.API.DebugFolder, "ocr-structured-request"); err != nil {
+			return "", Usage{}, fmt.Errorf("failed to save debug script: %w", err)
+		}
+	}
+
+	resp, err := c.doRequestWithRetry(req)
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", Usage{}, fmt.Errorf("unexpected status code: %d, response: %s", resp.StatusCode, string(body))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("failed to read response: %w", err)
 	}
+
+	var chatResp ChatResponse
+	if err := json.Unmarshal(body, &chatResp); err != nil {
+		return "", Usage{}, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(chatResp.Choices) == 0 {
+		return "", Usage{}, fmt.Errorf("no choices in response")
+	}
+
+	return chatResp.Choices[0].Message.Content, chatResp.Usage, nil
+}
+
+// ListModels delegates to the configured Backend.
+func (c *LLMClient) ListModels(ctx context.Context) ([]string, error) {
+	return c.backend.ListModels(ctx)
 }
 
-func (c *LLMClient) CheckConnection() error {
+type embeddingRequest struct {
+	Model string `json:"model"`
+	Input string `json:"input"`
+}
+
+type embeddingData struct {
+	Embedding []float32 `json:"embedding"`
+}
+
+type embeddingResponse struct {
+	Data  []embeddingData `json:"data"`
+	Usage Usage           `json:"usage"`
+}
+
+// Embed delegates to the configured Backend. Results are cached on disk
+// keyed by a hash of (text, model), so re-embedding the same text - e.g. a
+// document whose content hasn't changed between runs, or the same semantic
+// filter query every run - is instant and free. It is used by
+// internal/tagger to score documents against tag centroids and by
+// PaperlessClient's FilterTypeSemantic filter.
+func (c *LLMClient) Embed(ctx context.Context, text string) ([]float32, error) {
+	key := cache.KeyForEmbedding(text, c.config.LLM.Models.EmbeddingModel)
+	if cached, ok := c.getCached(key); ok {
+		var vec []float32
+		if err := json.Unmarshal(cached, &vec); err == nil {
+			return vec, nil
+		}
+	}
+
+	vec, err := c.backend.Embed(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	c.setCached(key, vec)
+	return vec, nil
+}
+
+// embedOpenAI calls the OpenAI-compatible /v1/embeddings endpoint with
+// config.LLM.Models.EmbeddingModel and returns the resulting vector.
+func (c *LLMClient) embedOpenAI(ctx context.Context, text string) ([]float32, error) {
+	url := strings.TrimSuffix(c.config.LLM.API.BaseURL, "/") + "/v1/embeddings"
+
+	reqBody, err := json.Marshal(embeddingRequest{Model: c.config.LLM.Models.EmbeddingModel, Input: text})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	ctx, cancel := c.withDeadline(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.doRequestWithRetry(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status code: %d, response: %s", resp.StatusCode, string(body))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var embResp embeddingResponse
+	if err := json.Unmarshal(body, &embResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(embResp.Data) == 0 {
+		return nil, fmt.Errorf("no embedding in response")
+	}
+
+	c.ledger.Record(0, c.config.LLM.Models.EmbeddingModel, embResp.Usage)
+
+	return embResp.Data[0].Embedding, nil
+}
+
+func (c *LLMClient) checkConnectionOpenAI(ctx context.Context) error {
 	// Check models endpoint
 	url := strings.TrimSuffix(c.config.LLM.API.BaseURL, "/") + "/v1/models"
 
-	req, err := http.NewRequest("GET", url, nil)
+	ctx, cancel := c.withDeadline(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -189,6 +912,7 @@ func (c *LLMClient) CheckConnection() error {
 	// Check if required models are available
 	titleModelAvailable := false
 	contentModelAvailable := false
+	embeddingModelAvailable := false
 
 	allRemoteModelsIds := []string{}
 
@@ -200,6 +924,9 @@ func (c *LLMClient) CheckConnection() error {
 		if model.ID == c.config.LLM.Models.OCR {
 			contentModelAvailable = true
 		}
+		if model.ID == c.config.LLM.Models.EmbeddingModel {
+			embeddingModelAvailable = true
+		}
 	}
 
 	if !titleModelAvailable {
@@ -209,11 +936,14 @@ func (c *LLMClient) CheckConnection() error {
 
 		return fmt.Errorf("content extraction model '%s' not available, found %v", c.config.LLM.Models.OCR, allRemoteModelsIds)
 	}
+	if c.config.LLM.Models.EmbeddingModel != "" && !embeddingModelAvailable {
+		return fmt.Errorf("embedding model '%s' not available, found %v", c.config.LLM.Models.EmbeddingModel, allRemoteModelsIds)
+	}
 
 	return nil
 }
 
-func (c *LLMClient) GenerateTitleFromContent(content string) (CaptionResponse, error) {
+func (c *LLMClient) generateTitleFromContentOpenAI(ctx context.Context, documentID int, content string) (CaptionResponse, error) {
 	if content == "" {
 		return CaptionResponse{
 			Summarize: "Empty document content",
@@ -234,10 +964,11 @@ func (c *LLMClient) GenerateTitleFromContent(content string) (CaptionResponse, e
 	prompt = strings.ReplaceAll(prompt, "{content}", content)
 	prompt = strings.ReplaceAll(prompt, "{truncate_chars}", fmt.Sprintf("%d", c.config.Processing.TitleGeneration.TruncateCharactersOfContent))
 
-	response, err := c.sendStructuredChatRequest(c.config.LLM.Models.TitleGeneration, prompt)
+	response, usage, err := c.sendStructuredChatRequest(ctx, c.config.LLM.Models.TitleGeneration, prompt, schema_title_generation)
 	if err != nil {
 		return CaptionResponse{}, fmt.Errorf("failed to generate title: %w", err)
 	}
+	c.ledger.Record(documentID, c.config.LLM.Models.TitleGeneration, usage)
 
 	// Parse the structured response
 	var captionResp CaptionResponse
@@ -259,21 +990,58 @@ func (c *LLMClient) GenerateTitleFromContent(content string) (CaptionResponse, e
 	return captionResp, nil
 }
 
-func (c *LLMClient) MakeOcr(imageData []byte) (string, error) {
-	// check if image data is jpg
-	if len(imageData) < 2 || (imageData[0] != 0xFF || imageData[1] != 0xD8) {
-		return "", fmt.Errorf("invalid image data: not a valid JPEG")
-	}
-
-	response, err := c.sendOCRRequest(c.config.LLM.Models.OCR, c.config.LLM.Prompts.OCR, imageData)
+func (c *LLMClient) makeOcrOpenAI(ctx context.Context, documentID int, imageData []byte) (string, error) {
+	response, usage, err := c.sendOCRRequest(ctx, c.config.LLM.Models.OCR, c.config.LLM.Prompts.OCR, imageData)
 	if err != nil {
 		return "", fmt.Errorf("failed to extract content: %w", err)
 	}
+	c.ledger.Record(documentID, c.config.LLM.Models.OCR, usage)
 
 	return response, nil
 }
 
-func (c *LLMClient) sendOCRRequest(model, prompt string, imageData []byte) (string, error) {
+func (c *LLMClient) listModelsOpenAI(ctx context.Context) ([]string, error) {
+	url := strings.TrimSuffix(c.config.LLM.API.BaseURL, "/") + "/v1/models"
+
+	ctx, cancel := c.withDeadline(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.doRequestWithRetry(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var modelsResp ModelsResponse
+	if err := json.Unmarshal(body, &modelsResp); err != nil {
+		return nil, fmt.Errorf("failed to parse models response: %w", err)
+	}
+
+	ids := make([]string, 0, len(modelsResp.Data))
+	for _, model := range modelsResp.Data {
+		ids = append(ids, model.ID)
+	}
+	return ids, nil
+}
+
+func (c *LLMClient) sendOCRRequest(ctx context.Context, model, prompt string, imageData []byte) (string, Usage, error) {
 	url := strings.TrimSuffix(c.config.LLM.API.BaseURL, "/") + c.config.LLM.API.Endpoint
 
 	// Prepare base64 image and data URL (no mime type, as in your example)
@@ -303,12 +1071,15 @@ func (c *LLMClient) sendOCRRequest(model, prompt string, imageData []byte) (stri
 
 	reqBody, err := json.Marshal(chatReq)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return "", Usage{}, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewReader(reqBody))
+	ctx, cancel := c.withDeadline(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(reqBody))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return "", Usage{}, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -316,7 +1087,7 @@ func (c *LLMClient) sendOCRRequest(model, prompt string, imageData []byte) (stri
 	if c.config.LLM.API.Debug && c.config.LLM.API.DebugFolder != "" {
 		err = saveDebugScript(url, reqBody, c.config.LLM.API.DebugFolder, "ocr-request")
 		if err != nil {
-			return "", fmt.Errorf("failed to save debug script: %w", err)
+			return "", Usage{}, fmt.Errorf("failed to save debug script: %w", err)
 		} else {
 			fmt.Printf("Debug script saved to %s\n", c.config.LLM.API.DebugFolder)
 		}
@@ -324,7 +1095,7 @@ func (c *LLMClient) sendOCRRequest(model, prompt string, imageData []byte) (stri
 
 	resp, err := c.doRequestWithRetry(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return "", Usage{}, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer func() {
 		io.Copy(io.Discard, resp.Body)
@@ -333,24 +1104,24 @@ func (c *LLMClient) sendOCRRequest(model, prompt string, imageData []byte) (stri
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("unexpected status code: %d, response: %s", resp.StatusCode, string(body))
+		return "", Usage{}, fmt.Errorf("unexpected status code: %d, response: %s", resp.StatusCode, string(body))
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return "", Usage{}, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	var chatResp ChatResponse
 	if err := json.Unmarshal(body, &chatResp); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+		return "", Usage{}, fmt.Errorf("failed to parse response: %w", err)
 	}
 
 	if len(chatResp.Choices) == 0 {
-		return "", fmt.Errorf("no choices in response")
+		return "", Usage{}, fmt.Errorf("no choices in response")
 	}
 
-	return chatResp.Choices[0].Message.Content, nil
+	return chatResp.Choices[0].Message.Content, chatResp.Usage, nil
 }
 
 func saveDebugScript(url string, reqBody []byte, debugFolder string, name string) error {
@@ -430,18 +1201,18 @@ curl -X POST %s \
 // 	return chatResp.Choices[0].Message.Content, nil
 // }
 
-func (c *LLMClient) sendStructuredChatRequest(model, prompt string) (string, error) {
+func (c *LLMClient) sendStructuredChatRequest(ctx context.Context, model, prompt string, schemaDoc []byte) (string, Usage, error) {
 	url := strings.TrimSuffix(c.config.LLM.API.BaseURL, "/") + c.config.LLM.API.Endpoint
 
 	var schema interface{}
-	if err := json.Unmarshal(schema_title_generation, &schema); err != nil {
-		return "", fmt.Errorf("failed to parse schema: %w", err)
+	if err := json.Unmarshal(schemaDoc, &schema); err != nil {
+		return "", Usage{}, fmt.Errorf("failed to parse schema: %w", err)
 	}
 
 	// Extract the schema content from the parsed JSON
 	schemaMap, ok := schema.(map[string]interface{})
 	if !ok {
-		return "", fmt.Errorf("invalid schema format")
+		return "", Usage{}, fmt.Errorf("invalid schema format")
 	}
 
 	chatReq := ChatRequest{
@@ -464,12 +1235,15 @@ func (c *LLMClient) sendStructuredChatRequest(model, prompt string) (string, err
 
 	reqBody, err := json.Marshal(chatReq)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return "", Usage{}, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewReader(reqBody))
+	ctx, cancel := c.withDeadline(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(reqBody))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return "", Usage{}, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -477,7 +1251,7 @@ func (c *LLMClient) sendStructuredChatRequest(model, prompt string) (string, err
 	if c.config.LLM.API.Debug && c.config.LLM.API.DebugFolder != "" {
 		err = saveDebugScript(url, reqBody, c.config.LLM.API.DebugFolder, "title-request")
 		if err != nil {
-			return "", fmt.Errorf("failed to save debug script: %w", err)
+			return "", Usage{}, fmt.Errorf("failed to save debug script: %w", err)
 		} else {
 			fmt.Printf("Debug script saved to %s\n", c.config.LLM.API.DebugFolder)
 		}
@@ -485,7 +1259,7 @@ func (c *LLMClient) sendStructuredChatRequest(model, prompt string) (string, err
 
 	resp, err := c.doRequestWithRetry(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return "", Usage{}, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer func() {
 		io.Copy(io.Discard, resp.Body)
@@ -494,22 +1268,22 @@ func (c *LLMClient) sendStructuredChatRequest(model, prompt string) (string, err
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("unexpected status code: %d, response: %s", resp.StatusCode, string(body))
+		return "", Usage{}, fmt.Errorf("unexpected status code: %d, response: %s", resp.StatusCode, string(body))
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return "", Usage{}, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	var chatResp ChatResponse
 	if err := json.Unmarshal(body, &chatResp); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+		return "", Usage{}, fmt.Errorf("failed to parse response: %w", err)
 	}
 
 	if len(chatResp.Choices) == 0 {
-		return "", fmt.Errorf("no choices in response")
+		return "", Usage{}, fmt.Errorf("no choices in response")
 	}
 
-	return chatResp.Choices[0].Message.Content, nil
+	return chatResp.Choices[0].Message.Content, chatResp.Usage, nil
 }
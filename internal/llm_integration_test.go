@@ -4,6 +4,7 @@
 package internal
 
 import (
+	"context"
 	_ "embed"
 	"fmt"
 	"testing"
@@ -53,11 +54,13 @@ func TestLLMClient_GenerateTitleFromContent(t *testing.T) {
 	config := &cfg.Config{
 		LLM: cfg.LLMConfig{
 			API: struct {
-				BaseURL     string `yaml:"base_url"`
-				Endpoint    string `yaml:"endpoint"`
-				Timeout     int    `yaml:"timeout"`
-				Debug       bool   `yaml:"debug"`
-				DebugFolder string `yaml:"debug_folder"`
+				BaseURL     string                  `yaml:"base_url"`
+				Endpoint    string                  `yaml:"endpoint"`
+				Timeout     int                     `yaml:"timeout"`
+				Debug       bool                    `yaml:"debug"`
+				DebugFolder string                  `yaml:"debug_folder"`
+				Provider    string                  `yaml:"provider"`
+				Resilience  cfg.ResilienceConfig `yaml:"resilience"`
 			}{
 				BaseURL:     "http://localhost:8080",
 				Endpoint:    "/v1/chat/completions",
@@ -68,6 +71,7 @@ func TestLLMClient_GenerateTitleFromContent(t *testing.T) {
 			Models: struct {
 				TitleGeneration string `yaml:"title_generation"`
 				OCR             string `yaml:"ocr"`
+				EmbeddingModel  string `yaml:"embedding_model"`
 			}{
 				TitleGeneration: "ibnzterrell/Meta-Llama-3.3-70B-Instruct-AWQ-INT4",
 				OCR:             "",
@@ -117,7 +121,7 @@ func TestLLMClient_GenerateTitleFromContent(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := NewLLMClient(tt.fields.config)
-			got, err := c.GenerateTitleFromContent(tt.args.content)
+			got, err := c.GenerateTitleFromContent(context.Background(), 1, tt.args.content)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("LLMClient.GenerateTitleFromContent() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -142,11 +146,13 @@ func TestLLMClient_MakeOcrFrom(t *testing.T) {
 	config := &cfg.Config{
 		LLM: cfg.LLMConfig{
 			API: struct {
-				BaseURL     string `yaml:"base_url"`
-				Endpoint    string `yaml:"endpoint"`
-				Timeout     int    `yaml:"timeout"`
-				Debug       bool   `yaml:"debug"`
-				DebugFolder string `yaml:"debug_folder"`
+				BaseURL     string                  `yaml:"base_url"`
+				Endpoint    string                  `yaml:"endpoint"`
+				Timeout     int                     `yaml:"timeout"`
+				Debug       bool                    `yaml:"debug"`
+				DebugFolder string                  `yaml:"debug_folder"`
+				Provider    string                  `yaml:"provider"`
+				Resilience  cfg.ResilienceConfig `yaml:"resilience"`
 			}{
 				BaseURL:     "http://localhost:8080",
 				Endpoint:    "/v1/chat/completions",
@@ -157,6 +163,7 @@ func TestLLMClient_MakeOcrFrom(t *testing.T) {
 			Models: struct {
 				TitleGeneration string `yaml:"title_generation"`
 				OCR             string `yaml:"ocr"`
+				EmbeddingModel  string `yaml:"embedding_model"`
 			}{
 				TitleGeneration: "ibnzterrell/Meta-Llama-3.3-70B-Instruct-AWQ-INT4",
 				OCR:             "google/gemma-3-27b-it",
@@ -198,7 +205,7 @@ func TestLLMClient_MakeOcrFrom(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := NewLLMClient(tt.fields.config)
-			got, err := c.MakeOcr(tt.args.Data)
+			got, err := c.MakeOcr(context.Background(), 1, testasset_pdf, 0, tt.args.Data)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("LLMClient.MakeOcrFromPdf() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -1,13 +1,17 @@
 package internal
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
+	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/dhcgn/paperless-ngx-privatemode-ai/config"
@@ -17,6 +21,11 @@ import (
 type PaperlessClient struct {
 	config     *config.Config
 	httpClient *http.Client
+	// deadlineMu guards deadlineCh and deadlineTimer, armed by SetDeadline
+	// and consumed by withDeadline.
+	deadlineMu    sync.Mutex
+	deadlineCh    chan struct{}
+	deadlineTimer *time.Timer
 }
 
 type Document struct {
@@ -28,16 +37,27 @@ type Document struct {
 }
 
 type DocumentsResponse struct {
-	Count   int        `json:"count"`
-	Results []Document `json:"results"`
+	Count    int        `json:"count"`
+	Next     string     `json:"next"`
+	Previous string     `json:"previous"`
+	Results  []Document `json:"results"`
 }
 
 // FilterType represents the type of document filter to apply
 type FilterType string
 
 const (
-	FilterTypeTitle   FilterType = "title"
-	FilterTypeContent FilterType = "content"
+	FilterTypeTitle    FilterType = "title"
+	FilterTypeContent  FilterType = "content"
+	FilterTypeSemantic FilterType = "semantic"
+)
+
+// defaultSemanticThreshold and defaultSemanticWindow are the fallbacks used
+// by filterBySemanticSimilarity when config.Filters.Semantic leaves
+// Threshold or Window unset.
+const (
+	defaultSemanticThreshold = 0.75
+	defaultSemanticWindow    = 4000
 )
 
 func NewPaperlessClient(config *config.Config) *PaperlessClient {
@@ -49,10 +69,61 @@ func NewPaperlessClient(config *config.Config) *PaperlessClient {
 	}
 }
 
-func (c *PaperlessClient) CheckConnection() error {
+// SetDeadline arms a one-shot deadline for requests made through c from now
+// on, mirroring the split read/write cancel-channel pattern used by
+// netstack's gonet adapter: a timer closes deadlineCh when t arrives, and
+// withDeadline derives a per-call context that ends early if it does.
+// CheckConnection, GetDocuments, IterateDocuments, UpdateDocument,
+// FilterDocuments and the GetTags/GetCorrespondents/GetDocumentTypes
+// lookups observe it; GetDocument, DownloadDocument and the GetOrCreate*
+// helpers use the caller's ctx as is and do not.
+// Calling it again replaces the previous deadline; a zero t disarms it.
+func (c *PaperlessClient) SetDeadline(t time.Time) {
+	c.deadlineMu.Lock()
+	defer c.deadlineMu.Unlock()
+	if c.deadlineTimer != nil {
+		c.deadlineTimer.Stop()
+	}
+	if t.IsZero() {
+		c.deadlineCh = nil
+		c.deadlineTimer = nil
+		return
+	}
+	ch := make(chan struct{})
+	c.deadlineCh = ch
+	c.deadlineTimer = time.AfterFunc(time.Until(t), func() { close(ch) })
+}
+
+// withDeadline derives a context from ctx that also ends when a timer armed
+// by SetDeadline fires, for request methods to pass to
+// http.NewRequestWithContext. The caller must invoke the returned cancel
+// once the request completes to release the goroutine watching deadlineCh.
+func (c *PaperlessClient) withDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	c.deadlineMu.Lock()
+	ch := c.deadlineCh
+	c.deadlineMu.Unlock()
+	if ch == nil {
+		return ctx, func() {}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-ch:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+func (c *PaperlessClient) CheckConnection(ctx context.Context) error {
 	url := strings.TrimSuffix(c.config.Paperless.API.BaseURL, "/") + "/api/documents/?page_size=1"
 
-	req, err := http.NewRequest("GET", url, nil)
+	ctx, cancel := c.withDeadline(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -72,13 +143,22 @@ func (c *PaperlessClient) CheckConnection() error {
 	return nil
 }
 
-func (c *PaperlessClient) GetDocuments() ([]Document, error) {
+// GetDocuments fetches a single page of documents (page_size from
+// config.Paperless.API.PageSize, or Paperless's own default if unset) and
+// silently drops everything past it. Kept for callers that genuinely only
+// want one page; everything that means "every document" should use
+// GetAllDocuments or IterateDocuments instead, which follow DocumentsResponse's
+// "next" link until exhausted.
+func (c *PaperlessClient) GetDocuments(ctx context.Context) ([]Document, error) {
 	url := strings.TrimSuffix(c.config.Paperless.API.BaseURL, "/") + "/api/documents/"
 	if c.config.Paperless.API.PageSize > 0 {
 		url += "?page_size=" + strconv.Itoa(c.config.Paperless.API.PageSize)
 	}
 
-	req, err := http.NewRequest("GET", url, nil)
+	ctx, cancel := c.withDeadline(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -108,10 +188,213 @@ func (c *PaperlessClient) GetDocuments() ([]Document, error) {
 	return response.Results, nil
 }
 
-// DownloadDocument
-func (c *PaperlessClient) DownloadDocument(documentID int) ([]byte, error) {
+// defaultIteratePageSize is the page_size IterateDocuments requests when
+// config.Paperless.API.PageSize is unset.
+const defaultIteratePageSize = 100
+
+// IterateOptions narrows IterateDocuments/GetAllDocuments to a subset of
+// documents using query params Paperless's /api/documents/ endpoint already
+// supports, so a caller can filter server-side before FilterDocuments's
+// regex/semantic matching ever sees a document. A zero value fetches every
+// document, in Paperless's default order.
+type IterateOptions struct {
+	// Ordering is a Paperless ordering field, e.g. "-created" for newest
+	// first (the "-" prefix reverses it).
+	Ordering string
+	// CreatedAfter filters to documents created after this timestamp
+	// (created__gt), in the RFC3339 format Paperless's API expects.
+	CreatedAfter string
+	// DocumentTypeID filters to documents with this document type
+	// (document_type__id). 0 leaves it unfiltered.
+	DocumentTypeID int
+	// TagsIDAll filters to documents carrying every one of these tag IDs
+	// (tags__id__all). Empty leaves it unfiltered.
+	TagsIDAll []int
+	// Query is a full-text search query (query=), evaluated by Paperless's
+	// own search backend rather than downloaded and regex-matched locally.
+	Query string
+}
+
+// IterateDocuments streams every document matching opts, following
+// DocumentsResponse.Next until Paperless reports no further page. It fetches
+// one page ahead of the caller (docCh is unbuffered), so a caller that stops
+// reading - or ctx being cancelled, checked both before each page request and
+// before each send - stops the walk without fetching pages nobody wants. The
+// error channel is buffered and carries at most one error, sent before either
+// channel is closed, so ranging over docCh to completion and then receiving
+// from errCh (as GetAllDocuments does) always sees the final error, if any.
+func (c *PaperlessClient) IterateDocuments(ctx context.Context, opts IterateOptions) (<-chan Document, <-chan error) {
+	docCh := make(chan Document)
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(docCh)
+		defer close(errCh)
+
+		ctx, cancel := c.withDeadline(ctx)
+		defer cancel()
+
+		nextURL := c.buildIterateURL(opts)
+		for nextURL != "" {
+			select {
+			case <-ctx.Done():
+				errCh <- ctx.Err()
+				return
+			default:
+			}
+
+			page, err := c.fetchDocumentsPage(ctx, nextURL)
+			if err != nil {
+				errCh <- err
+				return
+			}
+
+			for _, doc := range page.Results {
+				select {
+				case docCh <- doc:
+				case <-ctx.Done():
+					errCh <- ctx.Err()
+					return
+				}
+			}
+
+			nextURL = page.Next
+		}
+	}()
+
+	return docCh, errCh
+}
+
+// GetAllDocuments drains IterateDocuments with the zero IterateOptions (every
+// document, default order) into a slice, for callers that need the whole set
+// at once rather than streamed.
+func (c *PaperlessClient) GetAllDocuments(ctx context.Context) ([]Document, error) {
+	return c.GetAllDocumentsFiltered(ctx, IterateOptions{})
+}
+
+// GetAllDocumentsFiltered is GetAllDocuments with server-side filtering via
+// IterateOptions.
+func (c *PaperlessClient) GetAllDocumentsFiltered(ctx context.Context, opts IterateOptions) ([]Document, error) {
+	docCh, errCh := c.IterateDocuments(ctx, opts)
+
+	var documents []Document
+	for doc := range docCh {
+		documents = append(documents, doc)
+	}
+	if err := <-errCh; err != nil {
+		return nil, err
+	}
+	return documents, nil
+}
+
+// buildIterateURL builds the first page URL for opts. Subsequent pages come
+// from DocumentsResponse.Next, which Paperless already returns as a
+// fully-qualified URL carrying the same query params.
+func (c *PaperlessClient) buildIterateURL(opts IterateOptions) string {
+	pageSize := c.config.Paperless.API.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultIteratePageSize
+	}
+
+	q := url.Values{}
+	q.Set("page_size", strconv.Itoa(pageSize))
+	if opts.Ordering != "" {
+		q.Set("ordering", opts.Ordering)
+	}
+	if opts.CreatedAfter != "" {
+		q.Set("created__gt", opts.CreatedAfter)
+	}
+	if opts.DocumentTypeID > 0 {
+		q.Set("document_type__id", strconv.Itoa(opts.DocumentTypeID))
+	}
+	if len(opts.TagsIDAll) > 0 {
+		ids := make([]string, len(opts.TagsIDAll))
+		for i, id := range opts.TagsIDAll {
+			ids[i] = strconv.Itoa(id)
+		}
+		q.Set("tags__id__all", strings.Join(ids, ","))
+	}
+	if opts.Query != "" {
+		q.Set("query", opts.Query)
+	}
+
+	return strings.TrimSuffix(c.config.Paperless.API.BaseURL, "/") + "/api/documents/?" + q.Encode()
+}
+
+// fetchDocumentsPage GETs pageURL and parses it as a DocumentsResponse, for
+// IterateDocuments.
+func (c *PaperlessClient) fetchDocumentsPage(ctx context.Context, pageURL string) (DocumentsResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return DocumentsResponse{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	c.addHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return DocumentsResponse{}, fmt.Errorf("failed to get documents: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return DocumentsResponse{}, fmt.Errorf("unexpected status code: %d, response: %s", resp.StatusCode, string(body))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return DocumentsResponse{}, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var page DocumentsResponse
+	if err := json.Unmarshal(body, &page); err != nil {
+		return DocumentsResponse{}, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return page, nil
+}
+
+// GetDocument fetches a single document by ID, used by the webhook server
+// to look up the document named in an incoming post-consume event.
+func (c *PaperlessClient) GetDocument(ctx context.Context, documentID int) (Document, error) {
+	url := strings.TrimSuffix(c.config.Paperless.API.BaseURL, "/") + "/api/documents/" + strconv.Itoa(documentID) + "/"
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return Document{}, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	c.addHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return Document{}, fmt.Errorf("failed to get document: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Document{}, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Document{}, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var doc Document
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return Document{}, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return doc, nil
+}
+
+// DownloadDocument downloads documentID's PDF, aborting the request if ctx
+// is cancelled before it completes (see main.go's signal.NotifyContext
+// wiring for graceful shutdown of a long batch run).
+func (c *PaperlessClient) DownloadDocument(ctx context.Context, documentID int) ([]byte, error) {
 	url := strings.TrimSuffix(c.config.Paperless.API.BaseURL, "/") + "/api/documents/" + strconv.Itoa(documentID) + "/download/"
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -136,7 +419,7 @@ func (c *PaperlessClient) DownloadDocument(documentID int) ([]byte, error) {
 	return body, nil
 }
 
-func (c *PaperlessClient) UpdateDocument(documentID int, updates map[string]interface{}) error {
+func (c *PaperlessClient) UpdateDocument(ctx context.Context, documentID int, updates map[string]interface{}) error {
 	url := strings.TrimSuffix(c.config.Paperless.API.BaseURL, "/") + "/api/documents/" + strconv.Itoa(documentID) + "/"
 
 	updateData, err := json.Marshal(updates)
@@ -144,7 +427,10 @@ func (c *PaperlessClient) UpdateDocument(documentID int, updates map[string]inte
 		return fmt.Errorf("failed to marshal update data: %w", err)
 	}
 
-	req, err := http.NewRequest("PATCH", url, strings.NewReader(string(updateData)))
+	ctx, cancel := c.withDeadline(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "PATCH", url, strings.NewReader(string(updateData)))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -166,25 +452,48 @@ func (c *PaperlessClient) UpdateDocument(documentID int, updates map[string]inte
 	return nil
 }
 
-func (c *PaperlessClient) FilterDocuments(documents []Document, filterType FilterType) ([]Document, error) {
-	var filtered []Document
-	var patterns []string
+// FilterDocuments matches every document off documents against filterType's
+// configured criteria, checking ctx between documents so a long-running
+// filter pass can be aborted mid-stream. documents is a channel rather than
+// a slice so a caller feeding it from IterateDocuments never has to hold a
+// whole (possibly 50k-document) instance's documents in memory at once - see
+// main.go's signal.NotifyContext wiring and SetDeadline for how a run gets
+// cancelled. It returns the matching documents plus the total number seen
+// off documents, since a channel can no longer be len()'d the way the old
+// []Document parameter could. FilterTypeTitle and FilterTypeContent match
+// via the configured regex patterns; llmClient is unused for these and may
+// be nil. FilterTypeSemantic matches via embedding similarity instead (see
+// filterBySemanticSimilarity) and requires a non-nil llmClient.
+func (c *PaperlessClient) FilterDocuments(ctx context.Context, documents <-chan Document, filterType FilterType, llmClient *LLMClient) ([]Document, int, error) {
+	ctx, cancel := c.withDeadline(ctx)
+	defer cancel()
+
+	if filterType == FilterTypeSemantic {
+		return c.filterBySemanticSimilarity(ctx, documents, llmClient)
+	}
 
+	var patterns []string
 	switch filterType {
 	case FilterTypeTitle:
 		patterns = c.config.Filters.Title.Pattern
 	case FilterTypeContent:
 		patterns = c.config.Filters.Content.Pattern
 	default:
-		return nil, fmt.Errorf("unknown filter type: %s", filterType)
+		return nil, 0, fmt.Errorf("unknown filter type: %s", filterType)
 	}
 
-	progressBar, _ := pterm.DefaultProgressbar.
-		WithTitle("Filtering documents").
-		WithTotal(len(documents)).
-		Start()
+	spinner, _ := pterm.DefaultSpinner.Start("Filtering documents")
+
+	var filtered []Document
+	seen := 0
+	for doc := range documents {
+		select {
+		case <-ctx.Done():
+			spinner.Stop()
+			return filtered, seen, ctx.Err()
+		default:
+		}
 
-	for _, doc := range documents {
 		var targetText string
 		switch filterType {
 		case FilterTypeTitle:
@@ -197,8 +506,8 @@ func (c *PaperlessClient) FilterDocuments(documents []Document, filterType Filte
 		for _, pattern := range patterns {
 			regex, err := regexp.Compile(pattern)
 			if err != nil {
-				progressBar.Stop()
-				return nil, fmt.Errorf("invalid regex pattern '%s': %w", pattern, err)
+				spinner.Stop()
+				return nil, seen, fmt.Errorf("invalid regex pattern '%s': %w", pattern, err)
 			}
 
 			if regex.MatchString(targetText) {
@@ -211,11 +520,421 @@ func (c *PaperlessClient) FilterDocuments(documents []Document, filterType Filte
 			filtered = append(filtered, doc)
 		}
 
-		progressBar.Increment()
+		seen++
+		spinner.UpdateText(fmt.Sprintf("Filtering documents (%d checked, %d matched)", seen, len(filtered)))
+	}
+
+	spinner.Success(fmt.Sprintf("Filtered %d documents, %d matched", seen, len(filtered)))
+	return filtered, seen, nil
+}
+
+// filterBySemanticSimilarity implements FilterTypeSemantic: every query in
+// config.Filters.Semantic.Queries, plus the title+content of any document
+// named in ExampleDocumentIDs, is embedded once, then kept against every
+// document whose title+content embedding - chunked and mean-pooled when it
+// exceeds Window characters - has cosine similarity at or above Threshold
+// with the closest query. llmClient.Embed already caches on disk keyed by
+// (model, sha256(text)), so re-running over the same corpus only pays for
+// documents that are new or have changed.
+func (c *PaperlessClient) filterBySemanticSimilarity(ctx context.Context, documents <-chan Document, llmClient *LLMClient) ([]Document, int, error) {
+	if llmClient == nil {
+		return nil, 0, fmt.Errorf("filter type semantic requires an LLM client")
 	}
 
-	progressBar.Stop()
-	return filtered, nil
+	cfg := c.config.Filters.Semantic
+	queries := append([]string{}, cfg.Queries...)
+	for _, id := range cfg.ExampleDocumentIDs {
+		doc, err := c.GetDocument(ctx, id)
+		if err != nil {
+			pterm.Warning.Printf("filters.semantic.example_document_ids: document %d not found, skipping\n", id)
+			continue
+		}
+		queries = append(queries, doc.Title+"\n"+doc.Content)
+	}
+	if len(queries) == 0 {
+		return nil, 0, fmt.Errorf("filters.semantic requires at least one query or example_document_id")
+	}
+
+	threshold := cfg.Threshold
+	if threshold <= 0 {
+		threshold = defaultSemanticThreshold
+	}
+	window := cfg.Window
+	if window <= 0 {
+		window = defaultSemanticWindow
+	}
+
+	queryVectors := make([][]float32, 0, len(queries))
+	for _, query := range queries {
+		vec, err := llmClient.Embed(ctx, query)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to embed semantic filter query: %w", err)
+		}
+		queryVectors = append(queryVectors, vec)
+	}
+
+	spinner, _ := pterm.DefaultSpinner.Start("Filtering documents")
+
+	var filtered []Document
+	seen := 0
+	for doc := range documents {
+		select {
+		case <-ctx.Done():
+			spinner.Stop()
+			return filtered, seen, ctx.Err()
+		default:
+		}
+
+		vec, err := embedChunked(ctx, llmClient, doc.Title+"\n"+doc.Content, window)
+		if err != nil {
+			spinner.Stop()
+			return nil, seen, fmt.Errorf("failed to embed document %d: %w", doc.ID, err)
+		}
+
+		best := 0.0
+		for _, queryVec := range queryVectors {
+			if sim := cosineSimilarity(vec, queryVec); sim > best {
+				best = sim
+			}
+		}
+		if best >= threshold {
+			filtered = append(filtered, doc)
+		}
+
+		seen++
+		spinner.UpdateText(fmt.Sprintf("Filtering documents (%d checked, %d matched)", seen, len(filtered)))
+	}
+
+	spinner.Success(fmt.Sprintf("Filtered %d documents, %d matched", seen, len(filtered)))
+	return filtered, seen, nil
+}
+
+// embedChunked embeds text as a single vector via llmClient.Embed. Text
+// longer than window is split into window-sized chunks, each embedded and
+// mean-pooled into one vector, so a long document's embedding still
+// represents the whole thing rather than just its first window characters.
+func embedChunked(ctx context.Context, llmClient *LLMClient, text string, window int) ([]float32, error) {
+	if len(text) <= window {
+		return llmClient.Embed(ctx, text)
+	}
+
+	var sum []float32
+	chunks := 0
+	for start := 0; start < len(text); start += window {
+		end := start + window
+		if end > len(text) {
+			end = len(text)
+		}
+		vec, err := llmClient.Embed(ctx, text[start:end])
+		if err != nil {
+			return nil, err
+		}
+		if sum == nil {
+			sum = make([]float32, len(vec))
+		}
+		for i, v := range vec {
+			sum[i] += v
+		}
+		chunks++
+	}
+
+	pooled := make([]float32, len(sum))
+	for i, v := range sum {
+		pooled[i] = v / float32(chunks)
+	}
+	return pooled, nil
+}
+
+// cosineSimilarity returns the cosine similarity of a and b, or 0 if they
+// differ in length or either is a zero vector. Mirrors internal/tagger's
+// helper of the same name for the analogous centroid-similarity check.
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+type namedResource struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type namedResourceList struct {
+	Results []namedResource `json:"results"`
+}
+
+// TaxonomyItem is a Paperless tag, correspondent, or document type as
+// returned by GetTags/GetCorrespondents/GetDocumentTypes - the set of
+// choices LLMClient.ClassifyDocument's JSON schema constrains its reply to.
+type TaxonomyItem struct {
+	ID   int
+	Name string
+}
+
+// Taxonomy is the full set of tags, correspondents, and document types
+// currently defined in Paperless, passed to LLMClient.ClassifyDocument so
+// it can constrain its reply to IDs that actually exist.
+type Taxonomy struct {
+	Tags           []TaxonomyItem
+	Correspondents []TaxonomyItem
+	DocumentTypes  []TaxonomyItem
+}
+
+// taxonomyPageSize is large enough to pull every tag/correspondent/document
+// type in one page for the document sets this tool targets, the same
+// assumption getOrCreateNamedResource's name lookup already makes.
+const taxonomyPageSize = 1000
+
+// GetTags fetches every tag defined in Paperless.
+func (c *PaperlessClient) GetTags(ctx context.Context) ([]TaxonomyItem, error) {
+	return c.listTaxonomyItems(ctx, "tags")
+}
+
+// GetCorrespondents fetches every correspondent defined in Paperless.
+func (c *PaperlessClient) GetCorrespondents(ctx context.Context) ([]TaxonomyItem, error) {
+	return c.listTaxonomyItems(ctx, "correspondents")
+}
+
+// GetDocumentTypes fetches every document type defined in Paperless.
+func (c *PaperlessClient) GetDocumentTypes(ctx context.Context) ([]TaxonomyItem, error) {
+	return c.listTaxonomyItems(ctx, "document_types")
+}
+
+// listTaxonomyItems fetches every {"id", "name"} resource under endpoint,
+// the same resource shape getOrCreateNamedResource already looks up by
+// name.
+func (c *PaperlessClient) listTaxonomyItems(ctx context.Context, endpoint string) ([]TaxonomyItem, error) {
+	url := strings.TrimSuffix(c.config.Paperless.API.BaseURL, "/") + "/api/" + endpoint + "/?page_size=" + strconv.Itoa(taxonomyPageSize)
+
+	ctx, cancel := c.withDeadline(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	c.addHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status code fetching %s: %d, response: %s", endpoint, resp.StatusCode, string(body))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var list namedResourceList
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse %s response: %w", endpoint, err)
+	}
+
+	items := make([]TaxonomyItem, 0, len(list.Results))
+	for _, r := range list.Results {
+		items = append(items, TaxonomyItem{ID: r.ID, Name: r.Name})
+	}
+	return items, nil
+}
+
+// GetOrCreateTag resolves name to a tag ID, creating the tag if it doesn't
+// already exist. Used by ApplyRulesAction to turn a rule's tag names into
+// the IDs PATCH /api/documents/ expects.
+func (c *PaperlessClient) GetOrCreateTag(ctx context.Context, name string) (int, error) {
+	return c.getOrCreateNamedResource(ctx, "tags", name)
+}
+
+// GetOrCreateCorrespondent resolves name to a correspondent ID, creating it
+// if it doesn't already exist.
+func (c *PaperlessClient) GetOrCreateCorrespondent(ctx context.Context, name string) (int, error) {
+	return c.getOrCreateNamedResource(ctx, "correspondents", name)
+}
+
+// GetOrCreateDocumentType resolves name to a document type ID, creating it
+// if it doesn't already exist.
+func (c *PaperlessClient) GetOrCreateDocumentType(ctx context.Context, name string) (int, error) {
+	return c.getOrCreateNamedResource(ctx, "document_types", name)
+}
+
+// getOrCreateNamedResource looks up an existing tag/correspondent/document
+// type by exact (case-insensitive) name under endpoint, creating it if none
+// exists. Paperless NGX exposes tags, correspondents, and document types as
+// the same {"id", "name", ...} resource shape, so one implementation covers
+// all three.
+func (c *PaperlessClient) getOrCreateNamedResource(ctx context.Context, endpoint, name string) (int, error) {
+	listURL := strings.TrimSuffix(c.config.Paperless.API.BaseURL, "/") + "/api/" + endpoint + "/?name__iexact=" + url.QueryEscape(name)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", listURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	c.addHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up %s %q: %w", endpoint, name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, fmt.Errorf("unexpected status code looking up %s %q: %d, response: %s", endpoint, name, resp.StatusCode, string(body))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var list namedResourceList
+	if err := json.Unmarshal(body, &list); err != nil {
+		return 0, fmt.Errorf("failed to parse %s response: %w", endpoint, err)
+	}
+	for _, r := range list.Results {
+		if strings.EqualFold(r.Name, name) {
+			return r.ID, nil
+		}
+	}
+
+	return c.createNamedResource(ctx, endpoint, name)
+}
+
+// GetOrCreateCustomField resolves name to a custom field ID, creating it
+// with dataType (e.g. "string", "monetary") if it doesn't already exist.
+// Used by ExtractMetadataAction for the extracted fields that don't map to
+// a correspondent/document type/tag. Custom fields share the same
+// {"id", "name"} lookup shape as tags/correspondents/document types but
+// need a "data_type" when created, so it isn't routed through
+// getOrCreateNamedResource.
+func (c *PaperlessClient) GetOrCreateCustomField(ctx context.Context, name, dataType string) (int, error) {
+	listURL := strings.TrimSuffix(c.config.Paperless.API.BaseURL, "/") + "/api/custom_fields/?name__iexact=" + url.QueryEscape(name)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", listURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	c.addHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up custom field %q: %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, fmt.Errorf("unexpected status code looking up custom field %q: %d, response: %s", name, resp.StatusCode, string(body))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var list namedResourceList
+	if err := json.Unmarshal(body, &list); err != nil {
+		return 0, fmt.Errorf("failed to parse custom_fields response: %w", err)
+	}
+	for _, r := range list.Results {
+		if strings.EqualFold(r.Name, name) {
+			return r.ID, nil
+		}
+	}
+
+	return c.createCustomField(ctx, name, dataType)
+}
+
+func (c *PaperlessClient) createCustomField(ctx context.Context, name, dataType string) (int, error) {
+	createURL := strings.TrimSuffix(c.config.Paperless.API.BaseURL, "/") + "/api/custom_fields/"
+
+	payload, err := json.Marshal(map[string]string{"name": name, "data_type": dataType})
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal custom_fields payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", createURL, strings.NewReader(string(payload)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	c.addHeaders(req)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create custom field %q: %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, fmt.Errorf("unexpected status code creating custom field %q: %d, response: %s", name, resp.StatusCode, string(body))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var created namedResource
+	if err := json.Unmarshal(body, &created); err != nil {
+		return 0, fmt.Errorf("failed to parse custom_fields response: %w", err)
+	}
+	return created.ID, nil
+}
+
+func (c *PaperlessClient) createNamedResource(ctx context.Context, endpoint, name string) (int, error) {
+	createURL := strings.TrimSuffix(c.config.Paperless.API.BaseURL, "/") + "/api/" + endpoint + "/"
+
+	payload, err := json.Marshal(map[string]string{"name": name})
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal %s payload: %w", endpoint, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", createURL, strings.NewReader(string(payload)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	c.addHeaders(req)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create %s %q: %w", endpoint, name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, fmt.Errorf("unexpected status code creating %s %q: %d, response: %s", endpoint, name, resp.StatusCode, string(body))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var created namedResource
+	if err := json.Unmarshal(body, &created); err != nil {
+		return 0, fmt.Errorf("failed to parse %s response: %w", endpoint, err)
+	}
+	return created.ID, nil
 }
 
 func (c *PaperlessClient) addHeaders(req *http.Request) {
@@ -0,0 +1,51 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dhcgn/paperless-ngx-privatemode-ai/config"
+	"github.com/dhcgn/paperless-ngx-privatemode-ai/internal/render"
+)
+
+// RenderPageToJpg rasterizes page (0-based) of pdfBytes to a JPEG image,
+// using the backend selected by config.Tools.Renderer (imagemagick,
+// poppler, mupdf, or pdfcpu), or auto-detected when it is unset. See
+// internal/render for the Backend interface and its implementations. ctx is
+// forwarded to the backend, which shells out via exec.CommandContext where
+// applicable, so a cancelled batch run (see main.go's signal.NotifyContext
+// wiring) doesn't leave a rendering subprocess running after the document
+// loop stops.
+func RenderPageToJpg(ctx context.Context, cfg *config.Config, pdfBytes []byte, page int) ([]byte, error) {
+	backend, err := render.New(cfg.Tools)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select PDF render backend: %w", err)
+	}
+
+	opts := render.Options{
+		DPI:            cfg.Tools.RenderOptions.DPI,
+		TargetLongEdge: cfg.Tools.RenderOptions.TargetLongEdge,
+		JPEGQuality:    cfg.Tools.RenderOptions.JPEGQuality,
+	}
+
+	jpgBytes, err := backend.RenderPage(ctx, pdfBytes, page, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render page %d using %s: %w", page, backend.Name(), err)
+	}
+	return jpgBytes, nil
+}
+
+// PageCount reports how many pages pdfBytes has, using the same render
+// backend selection as RenderPageToJpg.
+func PageCount(ctx context.Context, cfg *config.Config, pdfBytes []byte) (int, error) {
+	backend, err := render.New(cfg.Tools)
+	if err != nil {
+		return 0, fmt.Errorf("failed to select PDF render backend: %w", err)
+	}
+
+	count, err := backend.PageCount(ctx, pdfBytes)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pages using %s: %w", backend.Name(), err)
+	}
+	return count, nil
+}
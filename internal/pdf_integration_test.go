@@ -4,6 +4,7 @@
 package internal
 
 import (
+	"context"
 	_ "embed"
 	"reflect"
 	"testing"
@@ -35,6 +36,7 @@ func TestConfig_RenderPageToJpg(t *testing.T) {
 			name: "Valid PDF with one page",
 			c: &config.Config{
 				Tools: config.ToolsConfig{
+					Renderer: "imagemagick",
 					ImagemagickForWindows: config.ImagemagickConfig{
 						FullPath: `C:\Program Files\ImageMagick-7.1.1-Q16-HDRI\magick.exe`, // For Windows testing
 					},
@@ -50,7 +52,7 @@ func TestConfig_RenderPageToJpg(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := RenderPageToJpg(tt.c, tt.args.pdfBytes, tt.args.page)
+			got, err := RenderPageToJpg(context.Background(), tt.c, tt.args.pdfBytes, tt.args.page)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Config.RenderPageToJpg() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -0,0 +1,60 @@
+package render
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dhcgn/paperless-ngx-privatemode-ai/config"
+	"github.com/pterm/pterm"
+)
+
+// autoBackend prefers primary - a renderer needing no external binary - and
+// falls back to secondary per call when primary fails to render a specific
+// page or count a specific PDF's pages, e.g. because the PDF uses a
+// feature primary doesn't support. This is a stronger guarantee than
+// autoDetect's one-time, install-time selection: a single malformed or
+// unusual PDF can't take down an entire batch run just because the
+// preferred backend chokes on it.
+type autoBackend struct {
+	primary   Backend
+	secondary Backend
+}
+
+// newAutoBackend builds an autoBackend with mupdf (pure Go API, no
+// subprocess) as primary and imagemagick as secondary. If only one of them
+// is available, that one is returned directly instead of being wrapped.
+func newAutoBackend(cfg config.ToolsConfig) (Backend, error) {
+	primary, primaryErr := newMuPDFBackend(cfg)
+	secondary, secondaryErr := newImageMagickBackend(cfg)
+
+	switch {
+	case primaryErr == nil && secondaryErr == nil:
+		return &autoBackend{primary: primary, secondary: secondary}, nil
+	case primaryErr == nil:
+		return primary, nil
+	case secondaryErr == nil:
+		return secondary, nil
+	default:
+		return nil, fmt.Errorf("no PDF render backend available for auto mode: %v, %v", primaryErr, secondaryErr)
+	}
+}
+
+func (b *autoBackend) Name() string { return string(NameAuto) }
+
+func (b *autoBackend) RenderPage(ctx context.Context, pdf []byte, page int, opts Options) ([]byte, error) {
+	jpgBytes, err := b.primary.RenderPage(ctx, pdf, page, opts)
+	if err == nil {
+		return jpgBytes, nil
+	}
+	pterm.Debug.Printf("%s failed to render page %d (%v), falling back to %s\n", b.primary.Name(), page, err, b.secondary.Name())
+	return b.secondary.RenderPage(ctx, pdf, page, opts)
+}
+
+func (b *autoBackend) PageCount(ctx context.Context, pdf []byte) (int, error) {
+	count, err := b.primary.PageCount(ctx, pdf)
+	if err == nil {
+		return count, nil
+	}
+	pterm.Debug.Printf("%s failed to count pages (%v), falling back to %s\n", b.primary.Name(), err, b.secondary.Name())
+	return b.secondary.PageCount(ctx, pdf)
+}
@@ -0,0 +1,48 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+)
+
+// Binarize decodes jpegData, converts it to pure black/white by a global
+// luminance threshold, and re-encodes it as JPEG. threshold is in [0, 1]:
+// a pixel darker than threshold*0xFFFF becomes black.
+//
+// This is a simplified, global-threshold approximation of Sauvola
+// binarization (Sauvola thresholds each pixel against the mean/stddev of a
+// local window around it), used by processor.OcrPipeline to try a few
+// thresholds per page and keep whichever OCRs best. A true local-adaptive
+// implementation is out of scope without a dedicated image-processing
+// dependency this project doesn't have.
+func Binarize(jpegData []byte, threshold float64, quality int) ([]byte, error) {
+	img, err := jpeg.Decode(bytes.NewReader(jpegData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode JPEG: %w", err)
+	}
+
+	bounds := img.Bounds()
+	out := image.NewGray(bounds)
+	cutoff := uint32(threshold * 0xFFFF)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			gray := color.GrayModel.Convert(img.At(x, y)).(color.Gray)
+			lum := uint32(gray.Y) * 0x101 // scale 8-bit gray to the 16-bit range threshold is expressed in
+			if lum < cutoff {
+				out.SetGray(x, y, color.Gray{Y: 0})
+			} else {
+				out.SetGray(x, y, color.Gray{Y: 0xFF})
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, out, &jpeg.Options{Quality: qualityOrDefault(quality)}); err != nil {
+		return nil, fmt.Errorf("failed to encode JPEG: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,63 @@
+package render
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+func encodeGrayJPEG(t *testing.T, gray uint8) []byte {
+	t.Helper()
+	img := image.NewGray(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			img.SetGray(x, y, color.Gray{Y: gray})
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatalf("failed to encode test JPEG: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestBinarize_ThresholdsToBlackOrWhite(t *testing.T) {
+	darkImage := encodeGrayJPEG(t, 10)
+	lightImage := encodeGrayJPEG(t, 245)
+
+	darkOut, err := Binarize(darkImage, 0.5, 90)
+	if err != nil {
+		t.Fatalf("Binarize(dark): %v", err)
+	}
+	lightOut, err := Binarize(lightImage, 0.5, 90)
+	if err != nil {
+		t.Fatalf("Binarize(light): %v", err)
+	}
+
+	darkDecoded, err := jpeg.Decode(bytes.NewReader(darkOut))
+	if err != nil {
+		t.Fatalf("decode dark output: %v", err)
+	}
+	lightDecoded, err := jpeg.Decode(bytes.NewReader(lightOut))
+	if err != nil {
+		t.Fatalf("decode light output: %v", err)
+	}
+
+	darkGray := color.GrayModel.Convert(darkDecoded.At(0, 0)).(color.Gray)
+	lightGray := color.GrayModel.Convert(lightDecoded.At(0, 0)).(color.Gray)
+
+	if darkGray.Y != 0 {
+		t.Errorf("dark pixel binarized to %d, want 0 (black)", darkGray.Y)
+	}
+	if lightGray.Y != 0xFF {
+		t.Errorf("light pixel binarized to %d, want 255 (white)", lightGray.Y)
+	}
+}
+
+func TestBinarize_InvalidJPEGErrors(t *testing.T) {
+	if _, err := Binarize([]byte("not a jpeg"), 0.5, 90); err == nil {
+		t.Error("expected an error decoding invalid JPEG data, got nil")
+	}
+}
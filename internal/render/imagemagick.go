@@ -0,0 +1,136 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/dhcgn/paperless-ngx-privatemode-ai/config"
+)
+
+// imageMagickBackend shells out to ImageMagick's "magick" (or legacy
+// "convert") command, the original renderer this project shipped with.
+type imageMagickBackend struct {
+	binPath string
+}
+
+func newImageMagickBackend(cfg config.ToolsConfig) (Backend, error) {
+	path, err := imageMagickPath(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &imageMagickBackend{binPath: path}, nil
+}
+
+// imageMagickPath resolves the ImageMagick binary: the configured Windows
+// path on Windows (ImageMagick has no reliable PATH convention there), or
+// "magick"/"convert" on PATH elsewhere.
+func imageMagickPath(cfg config.ToolsConfig) (string, error) {
+	if runtime.GOOS == "windows" {
+		path := cfg.ImagemagickForWindows.FullPath
+		if path == "" {
+			return "", fmt.Errorf("tools.imagemagick-for-windows.fullpath not set")
+		}
+		if _, err := os.Stat(path); err != nil {
+			return "", fmt.Errorf("tools.imagemagick-for-windows.fullpath does not exist or is not accessible: %w", err)
+		}
+		return path, nil
+	}
+	if path, err := exec.LookPath("magick"); err == nil {
+		return path, nil
+	}
+	if path, err := exec.LookPath("convert"); err == nil {
+		return path, nil
+	}
+	return "", fmt.Errorf("ImageMagick not found in PATH")
+}
+
+func (b *imageMagickBackend) Name() string { return string(NameImageMagick) }
+
+// PageCount shells out to "identify", which prints one line per page/frame
+// of a multi-page PDF.
+func (b *imageMagickBackend) PageCount(ctx context.Context, pdf []byte) (int, error) {
+	pdfFile, err := os.CreateTemp("", "input-*.pdf")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create temp PDF file: %w", err)
+	}
+	defer os.Remove(pdfFile.Name())
+
+	if _, err := pdfFile.Write(pdf); err != nil {
+		pdfFile.Close()
+		return 0, fmt.Errorf("failed to write PDF bytes: %w", err)
+	}
+	pdfFile.Close()
+
+	identifyPath, identifyArgs := identifyCommand(b.binPath)
+	cmd := exec.CommandContext(ctx, identifyPath, append(identifyArgs, "-format", "%n\\n", pdfFile.Name())...)
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("identify failed: %w", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	count, err := strconv.Atoi(strings.TrimSpace(lines[0]))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse page count from identify output %q: %w", output, err)
+	}
+	return count, nil
+}
+
+// identifyCommand derives the "identify" invocation from the resolved
+// "magick"/"convert" path: ImageMagick 7's magick binary takes identify as a
+// subcommand ("magick identify ..."), while the legacy ImageMagick 6 layout
+// ships a separate "identify" binary alongside "convert".
+func identifyCommand(magickBinPath string) (string, []string) {
+	base := filepath.Base(magickBinPath)
+	if base == "convert" || base == "convert.exe" {
+		return filepath.Join(filepath.Dir(magickBinPath), "identify"), nil
+	}
+	return magickBinPath, []string{"identify"}
+}
+
+func (b *imageMagickBackend) RenderPage(ctx context.Context, pdf []byte, page int, opts Options) ([]byte, error) {
+	pdfFile, err := os.CreateTemp("", "input-*.pdf")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp PDF file: %w", err)
+	}
+	defer os.Remove(pdfFile.Name())
+
+	if _, err := pdfFile.Write(pdf); err != nil {
+		pdfFile.Close()
+		return nil, fmt.Errorf("failed to write PDF bytes: %w", err)
+	}
+	pdfFile.Close()
+
+	jpgFile, err := os.CreateTemp("", "output-*.jpg")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp JPG file: %w", err)
+	}
+	jpgFilePath := jpgFile.Name()
+	jpgFile.Close()
+	defer os.Remove(jpgFilePath)
+
+	// ImageMagick uses 0-based page index: input.pdf[0] for the first page.
+	args := []string{"-density", strconv.Itoa(dpiOrDefault(opts.DPI)), fmt.Sprintf("%s[%d]", pdfFile.Name(), page)}
+	if opts.TargetLongEdge > 0 {
+		args = append(args, "-resize", fmt.Sprintf("%dx%d>", opts.TargetLongEdge, opts.TargetLongEdge))
+	}
+	args = append(args, "-quality", strconv.Itoa(qualityOrDefault(opts.JPEGQuality)), jpgFilePath)
+
+	cmd := exec.CommandContext(ctx, b.binPath, args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("ImageMagick failed: %w, output: %s", err, string(output))
+	}
+
+	jpgBytes, err := os.ReadFile(jpgFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read output JPG: %w", err)
+	}
+	return jpgBytes, nil
+}
@@ -0,0 +1,65 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+
+	"github.com/dhcgn/paperless-ngx-privatemode-ai/config"
+	"github.com/gen2brain/go-fitz"
+)
+
+// muPDFBackend renders pages with go-fitz, a binding to MuPDF that needs no
+// external rasterizer binary, making it the preferred auto-detected
+// default.
+type muPDFBackend struct{}
+
+func newMuPDFBackend(config.ToolsConfig) (Backend, error) {
+	return &muPDFBackend{}, nil
+}
+
+func (b *muPDFBackend) Name() string { return string(NameMuPDF) }
+
+// PageCount and RenderPage only check ctx up front: go-fitz has no
+// context-aware API to cancel mid-call, unlike the backends that shell out
+// to an external tool via exec.CommandContext.
+func (b *muPDFBackend) PageCount(ctx context.Context, pdf []byte) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	doc, err := fitz.NewFromMemory(pdf)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open PDF: %w", err)
+	}
+	defer doc.Close()
+	return doc.NumPage(), nil
+}
+
+func (b *muPDFBackend) RenderPage(ctx context.Context, pdf []byte, page int, opts Options) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	doc, err := fitz.NewFromMemory(pdf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PDF: %w", err)
+	}
+	defer doc.Close()
+
+	rendered, err := doc.ImageDPI(page, float64(dpiOrDefault(opts.DPI)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to render page %d: %w", page, err)
+	}
+
+	var img image.Image = rendered
+	if opts.TargetLongEdge > 0 {
+		img = resizeToLongEdge(rendered, opts.TargetLongEdge)
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: qualityOrDefault(opts.JPEGQuality)}); err != nil {
+		return nil, fmt.Errorf("failed to encode JPEG: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,31 @@
+package render
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dhcgn/paperless-ngx-privatemode-ai/config"
+)
+
+// pdfcpuBackend would render pages via pdfcpu. pdfcpu is a PDF
+// *manipulation* library (merge, split, extract embedded images, ...); it
+// has no API for rasterizing a page's full content to an image the way
+// ImageMagick, Poppler, and MuPDF do, so it can't satisfy Backend. It's
+// kept as a selectable config.Tools.Renderer value, with a clear error
+// instead of a silently wrong render, so "pdfcpu" isn't a confusing dead
+// end in the config docs.
+type pdfcpuBackend struct{}
+
+func newPDFCPUBackend(config.ToolsConfig) (Backend, error) {
+	return nil, fmt.Errorf("pdfcpu backend is not available: pdfcpu has no page-rasterization API; use imagemagick, poppler, or mupdf instead")
+}
+
+func (b *pdfcpuBackend) Name() string { return string(NamePDFCPU) }
+
+func (b *pdfcpuBackend) RenderPage(ctx context.Context, pdf []byte, page int, opts Options) ([]byte, error) {
+	return nil, fmt.Errorf("pdfcpu backend is not available: pdfcpu has no page-rasterization API; use imagemagick, poppler, or mupdf instead")
+}
+
+func (b *pdfcpuBackend) PageCount(ctx context.Context, pdf []byte) (int, error) {
+	return 0, fmt.Errorf("pdfcpu backend is not available: pdfcpu has no page-rasterization API; use imagemagick, poppler, or mupdf instead")
+}
@@ -0,0 +1,119 @@
+package render
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/dhcgn/paperless-ngx-privatemode-ai/config"
+)
+
+// popplerBackend shells out to Poppler's "pdftoppm" command.
+type popplerBackend struct {
+	binPath string
+}
+
+func newPopplerBackend(config.ToolsConfig) (Backend, error) {
+	path, err := exec.LookPath("pdftoppm")
+	if err != nil {
+		return nil, fmt.Errorf("pdftoppm not found in PATH: %w", err)
+	}
+	return &popplerBackend{binPath: path}, nil
+}
+
+func (b *popplerBackend) Name() string { return string(NamePoppler) }
+
+// PageCount shells out to Poppler's "pdfinfo" and parses its "Pages:" line.
+func (b *popplerBackend) PageCount(ctx context.Context, pdf []byte) (int, error) {
+	pdfinfoPath, err := exec.LookPath("pdfinfo")
+	if err != nil {
+		return 0, fmt.Errorf("pdfinfo not found in PATH: %w", err)
+	}
+
+	pdfFile, err := os.CreateTemp("", "input-*.pdf")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create temp PDF file: %w", err)
+	}
+	defer os.Remove(pdfFile.Name())
+
+	if _, err := pdfFile.Write(pdf); err != nil {
+		pdfFile.Close()
+		return 0, fmt.Errorf("failed to write PDF bytes: %w", err)
+	}
+	pdfFile.Close()
+
+	cmd := exec.CommandContext(ctx, pdfinfoPath, pdfFile.Name())
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("pdfinfo failed: %w", err)
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if rest, ok := strings.CutPrefix(line, "Pages:"); ok {
+			count, err := strconv.Atoi(strings.TrimSpace(rest))
+			if err != nil {
+				return 0, fmt.Errorf("failed to parse pdfinfo page count from %q: %w", line, err)
+			}
+			return count, nil
+		}
+	}
+	return 0, fmt.Errorf("pdfinfo output did not contain a Pages: line")
+}
+
+func (b *popplerBackend) RenderPage(ctx context.Context, pdf []byte, page int, opts Options) ([]byte, error) {
+	pdfFile, err := os.CreateTemp("", "input-*.pdf")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp PDF file: %w", err)
+	}
+	defer os.Remove(pdfFile.Name())
+
+	if _, err := pdfFile.Write(pdf); err != nil {
+		pdfFile.Close()
+		return nil, fmt.Errorf("failed to write PDF bytes: %w", err)
+	}
+	pdfFile.Close()
+
+	outDir, err := os.MkdirTemp("", "pdftoppm-out")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp output directory: %w", err)
+	}
+	defer os.RemoveAll(outDir)
+	outPrefix := filepath.Join(outDir, "page")
+
+	// pdftoppm uses 1-based page numbers.
+	pageOneBased := strconv.Itoa(page + 1)
+	args := []string{
+		"-jpeg",
+		"-r", strconv.Itoa(dpiOrDefault(opts.DPI)),
+		"-jpegopt", fmt.Sprintf("quality=%d", qualityOrDefault(opts.JPEGQuality)),
+		"-f", pageOneBased,
+		"-l", pageOneBased,
+		// -singlefile drops pdftoppm's page-number suffix (which it
+		// zero-pads to the source document's total page-count digit-width,
+		// not a fixed width), so the output path is always outPrefix+".jpg".
+		"-singlefile",
+	}
+	if opts.TargetLongEdge > 0 {
+		args = append(args, "-scale-to", strconv.Itoa(opts.TargetLongEdge))
+	}
+	args = append(args, pdfFile.Name(), outPrefix)
+
+	cmd := exec.CommandContext(ctx, b.binPath, args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("pdftoppm failed: %w, output: %s", err, string(output))
+	}
+
+	data, err := os.ReadFile(outPrefix + ".jpg")
+	if err != nil {
+		return nil, fmt.Errorf("pdftoppm did not produce an output file for page %d: %w", page, err)
+	}
+	return data, nil
+}
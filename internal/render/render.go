@@ -0,0 +1,146 @@
+// Package render rasterizes a single page of a PDF document to a JPEG image
+// via a pluggable Backend, so paperless-ngx-privatemode-ai isn't hard-wired
+// to a single PDF tool. config.Tools.Renderer selects a specific Backend by
+// name, or New auto-detects the first available one when it is unset.
+package render
+
+import (
+	"context"
+	"fmt"
+	"image"
+
+	"github.com/dhcgn/paperless-ngx-privatemode-ai/config"
+)
+
+// Options controls how a page is rasterized, trading OCR fidelity for LLM
+// image-token cost: a higher DPI or TargetLongEdge produces a larger, more
+// legible image at a higher cost.
+type Options struct {
+	// DPI is the resolution page content is rendered at. 0 falls back to a
+	// built-in default (150).
+	DPI int
+	// TargetLongEdge downscales the rendered image so its longest side is
+	// at most this many pixels. 0 disables downscaling.
+	TargetLongEdge int
+	// JPEGQuality is the JPEG encoding quality (1-100). 0 falls back to a
+	// built-in default (85).
+	JPEGQuality int
+}
+
+// Backend rasterizes a single page of a PDF document to a JPEG image. Both
+// methods take ctx so a backend that shells out to an external tool can tie
+// the subprocess's lifetime to it via exec.CommandContext, letting a
+// cancelled batch run (see main.go's signal.NotifyContext wiring) kill an
+// in-flight render instead of leaving it running in the background.
+type Backend interface {
+	// RenderPage renders page (0-based) of pdf to a JPEG image.
+	RenderPage(ctx context.Context, pdf []byte, page int, opts Options) ([]byte, error)
+	// PageCount reports how many pages pdf has, so a caller can render
+	// every page instead of just one (see processor.OcrPipeline).
+	PageCount(ctx context.Context, pdf []byte) (int, error)
+	// Name identifies the backend, for logging and error messages.
+	Name() string
+}
+
+// Name identifies which Backend implementation to use.
+type Name string
+
+const (
+	NameImageMagick Name = "imagemagick"
+	NamePoppler     Name = "poppler"
+	NameMuPDF       Name = "mupdf"
+	NamePDFCPU      Name = "pdfcpu"
+	// NameAuto renders with mupdf and falls back to imagemagick per call
+	// (see autoBackend), unlike an unset cfg.Renderer which only picks one
+	// backend once, at startup (see autoDetect).
+	NameAuto Name = "auto"
+)
+
+// New selects a Backend by cfg.Renderer, or auto-detects the first
+// available one (preferring backends that need no external binary) when
+// cfg.Renderer is unset.
+func New(cfg config.ToolsConfig) (Backend, error) {
+	switch Name(cfg.Renderer) {
+	case NameImageMagick:
+		return newImageMagickBackend(cfg)
+	case NamePoppler:
+		return newPopplerBackend(cfg)
+	case NameMuPDF:
+		return newMuPDFBackend(cfg)
+	case NamePDFCPU:
+		return newPDFCPUBackend(cfg)
+	case NameAuto:
+		return newAutoBackend(cfg)
+	case "":
+		return autoDetect(cfg)
+	default:
+		return nil, fmt.Errorf("unknown tools.renderer: %q", cfg.Renderer)
+	}
+}
+
+// autoDetect tries each backend in order of preference and returns the
+// first one that is available, so a fresh install works without any
+// renderer-specific configuration.
+func autoDetect(cfg config.ToolsConfig) (Backend, error) {
+	candidates := []func(config.ToolsConfig) (Backend, error){
+		newMuPDFBackend,
+		newImageMagickBackend,
+		newPopplerBackend,
+		newPDFCPUBackend,
+	}
+
+	var errs []error
+	for _, newCandidate := range candidates {
+		backend, err := newCandidate(cfg)
+		if err == nil {
+			return backend, nil
+		}
+		errs = append(errs, err)
+	}
+	return nil, fmt.Errorf("no PDF render backend available: %v", errs)
+}
+
+func dpiOrDefault(dpi int) int {
+	if dpi <= 0 {
+		return 150
+	}
+	return dpi
+}
+
+func qualityOrDefault(quality int) int {
+	if quality <= 0 {
+		return 85
+	}
+	return quality
+}
+
+// resizeToLongEdge returns a nearest-neighbor downscale of img so its
+// longest side is at most target pixels. Images already at or under target
+// are returned unchanged; backends with a native resize flag (ImageMagick,
+// Poppler) don't need this and handle TargetLongEdge themselves.
+func resizeToLongEdge(img image.Image, target int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	longEdge := w
+	if h > longEdge {
+		longEdge = h
+	}
+	if target <= 0 || longEdge <= target {
+		return img
+	}
+
+	scale := float64(target) / float64(longEdge)
+	newW := int(float64(w) * scale)
+	newH := int(float64(h) * scale)
+
+	dst := image.NewRGBA(image.Rect(0, 0, newW, newH))
+	for y := 0; y < newH; y++ {
+		srcY := b.Min.Y + int(float64(y)/scale)
+		for x := 0; x < newW; x++ {
+			srcX := b.Min.X + int(float64(x)/scale)
+			dst.Set(x, y, img.At(srcX, srcY))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,188 @@
+package internal
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// circuitBreaker trips after failureThreshold consecutive failures,
+// rejecting requests for cooldown before letting a single probe request
+// through to test whether the backend has recovered.
+type circuitBreaker struct {
+	mu               sync.Mutex
+	failureThreshold int
+	cooldown         time.Duration
+	state            breakerState
+	consecutiveFails int
+	openedAt         time.Time
+}
+
+func newCircuitBreaker(failureThreshold, cooldownSeconds int) *circuitBreaker {
+	if failureThreshold <= 0 {
+		failureThreshold = 5
+	}
+	if cooldownSeconds <= 0 {
+		cooldownSeconds = 30
+	}
+	return &circuitBreaker{
+		failureThreshold: failureThreshold,
+		cooldown:         time.Duration(cooldownSeconds) * time.Second,
+	}
+}
+
+// allow reports whether a request may proceed, transitioning Open to
+// HalfOpen once the cooldown has elapsed so a single probe can get through.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != breakerOpen {
+		return true
+	}
+	if time.Since(b.openedAt) < b.cooldown {
+		return false
+	}
+	b.state = breakerHalfOpen
+	return true
+}
+
+func (b *circuitBreaker) recordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.consecutiveFails = 0
+	b.state = breakerClosed
+}
+
+func (b *circuitBreaker) recordFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == breakerHalfOpen {
+		// The probe request failed: reopen immediately without counting
+		// further towards failureThreshold.
+		b.state = breakerOpen
+		b.openedAt = time.Now()
+		return
+	}
+
+	b.consecutiveFails++
+	if b.consecutiveFails >= b.failureThreshold {
+		b.state = breakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+func (b *circuitBreaker) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state.String()
+}
+
+// rateLimiter is a token bucket refilled at requestsPerMinute/60 tokens per
+// second, capped at requestsPerMinute tokens so batch runs don't burst past
+// the configured quota.
+type rateLimiter struct {
+	mu         sync.Mutex
+	rate       float64 // tokens per second
+	capacity   float64
+	tokens     float64
+	lastRefill time.Time
+}
+
+func newRateLimiter(requestsPerMinute int) *rateLimiter {
+	if requestsPerMinute <= 0 {
+		return nil
+	}
+	return &rateLimiter{
+		rate:       float64(requestsPerMinute) / 60,
+		capacity:   float64(requestsPerMinute),
+		tokens:     float64(requestsPerMinute),
+		lastRefill: time.Now(),
+	}
+}
+
+// wait blocks until a token is available. A nil *rateLimiter (no limit
+// configured) never blocks.
+func (l *rateLimiter) wait() {
+	if l == nil {
+		return
+	}
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.tokens = math.Min(l.capacity, l.tokens+now.Sub(l.lastRefill).Seconds()*l.rate)
+		l.lastRefill = now
+
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mu.Unlock()
+			return
+		}
+		sleep := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		l.mu.Unlock()
+		time.Sleep(sleep)
+	}
+}
+
+// isRetryableStatus reports whether doRequestWithRetry should retry a
+// response with this status code.
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout, http.StatusTooEarly, http.StatusTooManyRequests,
+		http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// backoffDelay implements full-jitter exponential backoff: a random value in
+// [0, min(backoffCap, base*2^attempt)).
+func backoffDelay(attempt int, base, backoffCap time.Duration) time.Duration {
+	upper := base * time.Duration(uint64(1)<<uint(attempt))
+	if upper <= 0 || upper > backoffCap {
+		upper = backoffCap
+	}
+	return time.Duration(rand.Int63n(int64(upper)))
+}
+
+// retryAfterDelay parses a Retry-After header in either delta-seconds or
+// HTTP-date form, returning (delay, true) when present and valid.
+func retryAfterDelay(header string) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(header); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
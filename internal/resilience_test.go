@@ -0,0 +1,129 @@
+package internal
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker_OpensAfterThreshold(t *testing.T) {
+	b := newCircuitBreaker(2, 60)
+
+	if !b.allow() {
+		t.Fatal("fresh breaker should allow requests")
+	}
+	b.recordFailure()
+	if b.String() != "closed" {
+		t.Errorf("state after 1/2 failures = %s, want closed", b)
+	}
+	b.recordFailure()
+	if b.String() != "open" {
+		t.Errorf("state after 2/2 failures = %s, want open", b)
+	}
+	if b.allow() {
+		t.Error("open breaker should reject requests within cooldown")
+	}
+}
+
+func TestCircuitBreaker_HalfOpenProbeRecovers(t *testing.T) {
+	b := newCircuitBreaker(1, 0) // 0 cooldownSeconds falls back to 30s, so force it open then rewind openedAt
+	b.recordFailure()
+	if b.String() != "open" {
+		t.Fatalf("state = %s, want open", b)
+	}
+	b.openedAt = time.Now().Add(-time.Hour)
+
+	if !b.allow() {
+		t.Fatal("cooldown elapsed, probe request should be allowed")
+	}
+	if b.String() != "half-open" {
+		t.Errorf("state after cooldown = %s, want half-open", b)
+	}
+
+	b.recordSuccess()
+	if b.String() != "closed" {
+		t.Errorf("state after successful probe = %s, want closed", b)
+	}
+}
+
+func TestCircuitBreaker_HalfOpenProbeFailureReopens(t *testing.T) {
+	b := newCircuitBreaker(1, 0)
+	b.recordFailure()
+	b.openedAt = time.Now().Add(-time.Hour)
+	b.allow() // transitions to half-open
+
+	b.recordFailure()
+	if b.String() != "open" {
+		t.Errorf("state after failed probe = %s, want open", b)
+	}
+}
+
+func TestIsRetryableStatus(t *testing.T) {
+	tests := []struct {
+		code int
+		want bool
+	}{
+		{http.StatusOK, false},
+		{http.StatusBadRequest, false},
+		{http.StatusRequestTimeout, true},
+		{http.StatusTooManyRequests, true},
+		{http.StatusInternalServerError, true},
+		{http.StatusBadGateway, true},
+		{http.StatusServiceUnavailable, true},
+		{http.StatusGatewayTimeout, true},
+	}
+	for _, tt := range tests {
+		if got := isRetryableStatus(tt.code); got != tt.want {
+			t.Errorf("isRetryableStatus(%d) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestBackoffDelay_BoundedByCap(t *testing.T) {
+	base := 200 * time.Millisecond
+	upperBound := 1 * time.Second
+	for attempt := 0; attempt < 10; attempt++ {
+		d := backoffDelay(attempt, base, upperBound)
+		if d < 0 || d > upperBound {
+			t.Errorf("backoffDelay(%d) = %v, want within [0, %v]", attempt, d, upperBound)
+		}
+	}
+}
+
+func TestRetryAfterDelay(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		wantOK bool
+	}{
+		{"empty header", "", false},
+		{"delta seconds", "5", true},
+		{"invalid value", "not-a-delay", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok := retryAfterDelay(tt.header)
+			if ok != tt.wantOK {
+				t.Errorf("retryAfterDelay(%q) ok = %v, want %v", tt.header, ok, tt.wantOK)
+			}
+		})
+	}
+
+	if d, ok := retryAfterDelay("5"); !ok || d != 5*time.Second {
+		t.Errorf("retryAfterDelay(\"5\") = %v, %v, want 5s, true", d, ok)
+	}
+}
+
+func TestRateLimiter_NilLimiterNeverBlocks(t *testing.T) {
+	var l *rateLimiter
+	done := make(chan struct{})
+	go func() {
+		l.wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("nil rate limiter blocked wait()")
+	}
+}
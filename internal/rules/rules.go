@@ -0,0 +1,258 @@
+// Package rules implements the routing engine behind ApplyRulesAction: a
+// YAML rule list (config.RulesConfig) is compiled into matchers that test a
+// document's title/content (by regex, glob, or hash partition) and, on a
+// match, resolve a template-based action (set correspondent/document
+// type/tags) with placeholders filled in from the document, the match's
+// named capture groups, or the LLM.
+package rules
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dhcgn/paperless-ngx-privatemode-ai/config"
+)
+
+// Document is the subset of internal.Document rules match against, kept
+// independent of the internal package the way internal/tagger's Embedder
+// interface is, so this package doesn't need to import it.
+type Document struct {
+	ID          int
+	Title       string
+	Content     string
+	CreatedDate string
+}
+
+// Classifier answers a free-form question about a document, backing the
+// {{llm:"..."}} placeholder. internal.LLMClient is adapted to this by the
+// processor package.
+type Classifier interface {
+	Classify(doc Document, prompt string) (string, error)
+}
+
+// Action is a rule's resolved, placeholder-substituted outcome. A zero
+// value field means that rule doesn't set it.
+type Action struct {
+	Correspondent string
+	DocumentType  string
+	Tags          []string
+}
+
+// Rule is a compiled, ready-to-evaluate config.RuleConfig.
+type Rule struct {
+	name       string
+	conditions []condition
+	action     config.ActionConfig
+}
+
+// Name identifies the rule, for logging.
+func (r *Rule) Name() string { return r.name }
+
+type condition struct {
+	field   string
+	regex   *regexp.Regexp // used for field "title"/"content"
+	buckets int            // used for field "hash"
+	bucket  int
+}
+
+// Compile builds a Rule per entry in configs, pre-compiling every regex (or
+// glob, translated to one) so Match never fails on a malformed pattern at
+// evaluation time.
+func Compile(configs []config.RuleConfig) ([]*Rule, error) {
+	compiled := make([]*Rule, 0, len(configs))
+	for _, rc := range configs {
+		conditions := make([]condition, 0, len(rc.When))
+		for _, wc := range rc.When {
+			cond, err := compileCondition(wc)
+			if err != nil {
+				return nil, fmt.Errorf("rule %q: %w", rc.Name, err)
+			}
+			conditions = append(conditions, cond)
+		}
+		compiled = append(compiled, &Rule{name: rc.Name, conditions: conditions, action: rc.Then})
+	}
+	return compiled, nil
+}
+
+func compileCondition(wc config.ConditionConfig) (condition, error) {
+	if wc.Field == "hash" {
+		if wc.Buckets <= 0 {
+			return condition{}, fmt.Errorf("hash condition needs buckets > 0")
+		}
+		if wc.Bucket < 0 || wc.Bucket >= wc.Buckets {
+			return condition{}, fmt.Errorf("hash condition bucket %d out of range [0, %d)", wc.Bucket, wc.Buckets)
+		}
+		return condition{field: "hash", buckets: wc.Buckets, bucket: wc.Bucket}, nil
+	}
+
+	if wc.Field != "title" && wc.Field != "content" {
+		return condition{}, fmt.Errorf("unknown condition field %q (want \"title\", \"content\", or \"hash\")", wc.Field)
+	}
+
+	pattern := wc.Pattern
+	if wc.Glob {
+		pattern = globToRegexp(pattern)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return condition{}, fmt.Errorf("invalid pattern for field %q: %w", wc.Field, err)
+	}
+	return condition{field: wc.Field, regex: re}, nil
+}
+
+// globToRegexp translates a shell-style glob (* and ? wildcards) into an
+// anchored regexp, so globs are just sugar over the same regex matcher.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// Match reports whether every condition of r matches doc. On a match, it
+// also resolves r's action's templates (using doc, any named capture
+// groups the conditions matched, and classifier for {{llm:...}}) and
+// returns it.
+func (r *Rule) Match(doc Document, classifier Classifier) (Action, bool, error) {
+	vars := map[string]string{"year": yearOf(doc.CreatedDate)}
+
+	for _, c := range r.conditions {
+		if c.field == "hash" {
+			if int(hashBucket(doc.ID, c.buckets)) != c.bucket {
+				return Action{}, false, nil
+			}
+			continue
+		}
+
+		target := doc.Title
+		if c.field == "content" {
+			target = doc.Content
+		}
+		match := c.regex.FindStringSubmatch(target)
+		if match == nil {
+			return Action{}, false, nil
+		}
+		for i, name := range c.regex.SubexpNames() {
+			if i == 0 || name == "" {
+				continue
+			}
+			vars[name] = match[i]
+		}
+	}
+
+	action, err := resolveAction(r.action, doc, vars, classifier)
+	if err != nil {
+		return Action{}, false, err
+	}
+	return action, true, nil
+}
+
+// hashBucket deterministically assigns documentID to one of buckets
+// buckets, so otherwise-identical rule matches can be split across several
+// correspondents/tags for load balancing instead of all landing on one.
+func hashBucket(documentID, buckets int) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(strconv.Itoa(documentID)))
+	return h.Sum32() % uint32(buckets)
+}
+
+func yearOf(createdDate string) string {
+	if len(createdDate) < 4 {
+		return ""
+	}
+	return createdDate[:4]
+}
+
+func resolveAction(ac config.ActionConfig, doc Document, vars map[string]string, classifier Classifier) (Action, error) {
+	var action Action
+	var err error
+
+	if ac.Correspondent != "" {
+		if action.Correspondent, err = resolveTemplate(ac.Correspondent, doc, vars, classifier); err != nil {
+			return Action{}, err
+		}
+	}
+	if ac.DocumentType != "" {
+		if action.DocumentType, err = resolveTemplate(ac.DocumentType, doc, vars, classifier); err != nil {
+			return Action{}, err
+		}
+	}
+	for _, tag := range ac.Tags {
+		resolved, err := resolveTemplate(tag, doc, vars, classifier)
+		if err != nil {
+			return Action{}, err
+		}
+		action.Tags = append(action.Tags, resolved)
+	}
+	return action, nil
+}
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([^}]+?)\s*\}\}`)
+
+// resolveTemplate substitutes every {{...}} placeholder in tmpl:
+// {{title}}/{{content}}/{{year}} read from doc, {{extract:name}} reads a
+// named capture group a condition matched, and {{llm:"prompt"}} asks
+// classifier. A bare name that matches a captured named group (e.g.
+// {{who}}) also resolves to that group's value. Anything else is an unknown
+// placeholder and is an error,
+// same as an unresolvable OCR schema reference elsewhere in this project.
+func resolveTemplate(tmpl string, doc Document, vars map[string]string, classifier Classifier) (string, error) {
+	var resolveErr error
+	result := placeholderPattern.ReplaceAllStringFunc(tmpl, func(m string) string {
+		if resolveErr != nil {
+			return m
+		}
+		expr := placeholderPattern.FindStringSubmatch(m)[1]
+
+		switch {
+		case expr == "title":
+			return doc.Title
+		case expr == "content":
+			return doc.Content
+		case expr == "year":
+			return vars["year"]
+		case strings.HasPrefix(expr, "extract:"):
+			name := strings.TrimPrefix(expr, "extract:")
+			value, ok := vars[name]
+			if !ok {
+				resolveErr = fmt.Errorf("{{extract:%s}}: no condition captured a group named %q", name, name)
+			}
+			return value
+		case strings.HasPrefix(expr, "llm:"):
+			prompt := strings.Trim(strings.TrimPrefix(expr, "llm:"), `"`)
+			if classifier == nil {
+				resolveErr = fmt.Errorf("{{llm:%q}}: no LLM classifier configured", prompt)
+				return m
+			}
+			answer, err := classifier.Classify(doc, prompt)
+			if err != nil {
+				resolveErr = fmt.Errorf("{{llm:%q}}: %w", prompt, err)
+				return m
+			}
+			return answer
+		default:
+			if value, ok := vars[expr]; ok {
+				return value
+			}
+			resolveErr = fmt.Errorf("unknown placeholder %q", expr)
+			return m
+		}
+	})
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+	return result, nil
+}
@@ -0,0 +1,191 @@
+package rules
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dhcgn/paperless-ngx-privatemode-ai/config"
+)
+
+// stubClassifier answers every {{llm:"..."}} placeholder with a fixed
+// string, or fails if failWith is set.
+type stubClassifier struct {
+	answer   string
+	failWith error
+}
+
+func (c *stubClassifier) Classify(doc Document, prompt string) (string, error) {
+	if c.failWith != nil {
+		return "", c.failWith
+	}
+	return c.answer, nil
+}
+
+func TestCompile_InvalidConditionsRejected(t *testing.T) {
+	tests := []struct {
+		name string
+		rc   config.RuleConfig
+	}{
+		{
+			"unknown field",
+			config.RuleConfig{Name: "r", When: []config.ConditionConfig{{Field: "author", Pattern: "x"}}},
+		},
+		{
+			"bad regex",
+			config.RuleConfig{Name: "r", When: []config.ConditionConfig{{Field: "title", Pattern: "[unterminated"}}},
+		},
+		{
+			"hash without buckets",
+			config.RuleConfig{Name: "r", When: []config.ConditionConfig{{Field: "hash", Buckets: 0}}},
+		},
+		{
+			"hash bucket out of range",
+			config.RuleConfig{Name: "r", When: []config.ConditionConfig{{Field: "hash", Buckets: 2, Bucket: 2}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Compile([]config.RuleConfig{tt.rc}); err == nil {
+				t.Error("expected an error, got nil")
+			}
+		})
+	}
+}
+
+func TestRule_Match_TitleRegex(t *testing.T) {
+	rules, err := Compile([]config.RuleConfig{{
+		Name: "invoices",
+		When: []config.ConditionConfig{{Field: "title", Pattern: "^Invoice"}},
+		Then: config.ActionConfig{Correspondent: "Acme"},
+	}})
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+
+	action, matched, err := rules[0].Match(Document{Title: "Invoice 123"}, nil)
+	if err != nil || !matched {
+		t.Fatalf("Match = %v, %v, %v, want a match with no error", action, matched, err)
+	}
+	if action.Correspondent != "Acme" {
+		t.Errorf("Correspondent = %q, want Acme", action.Correspondent)
+	}
+
+	if _, matched, err := rules[0].Match(Document{Title: "Receipt 123"}, nil); err != nil || matched {
+		t.Errorf("non-matching title matched = %v, err = %v", matched, err)
+	}
+}
+
+func TestRule_Match_Glob(t *testing.T) {
+	rules, err := Compile([]config.RuleConfig{{
+		Name: "scans",
+		When: []config.ConditionConfig{{Field: "title", Pattern: "SCN_*.pdf", Glob: true}},
+		Then: config.ActionConfig{DocumentType: "Scan"},
+	}})
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+
+	if _, matched, _ := rules[0].Match(Document{Title: "SCN_001.pdf"}, nil); !matched {
+		t.Error("expected glob to match SCN_001.pdf")
+	}
+	if _, matched, _ := rules[0].Match(Document{Title: "not_a_scan.pdf"}, nil); matched {
+		t.Error("expected glob not to match not_a_scan.pdf")
+	}
+}
+
+func TestRule_Match_HashBucketIsDeterministic(t *testing.T) {
+	rules, err := Compile([]config.RuleConfig{{
+		Name: "split",
+		When: []config.ConditionConfig{{Field: "hash", Buckets: 2, Bucket: 0}},
+		Then: config.ActionConfig{Correspondent: "Bucket0"},
+	}})
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+
+	_, firstMatch, _ := rules[0].Match(Document{ID: 42}, nil)
+	_, secondMatch, _ := rules[0].Match(Document{ID: 42}, nil)
+	if firstMatch != secondMatch {
+		t.Error("hash bucket assignment is not stable across calls")
+	}
+}
+
+func TestRule_Match_ExtractPlaceholder(t *testing.T) {
+	rules, err := Compile([]config.RuleConfig{{
+		Name: "named-correspondent",
+		When: []config.ConditionConfig{{Field: "title", Pattern: `^Invoice from (?P<who>.+)$`}},
+		Then: config.ActionConfig{Correspondent: "{{extract:who}}"},
+	}})
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+
+	action, matched, err := rules[0].Match(Document{Title: "Invoice from Acme Corp"}, nil)
+	if err != nil || !matched {
+		t.Fatalf("Match = %v, %v, %v, want a match with no error", action, matched, err)
+	}
+	if action.Correspondent != "Acme Corp" {
+		t.Errorf("Correspondent = %q, want Acme Corp", action.Correspondent)
+	}
+}
+
+func TestRule_Match_YearPlaceholder(t *testing.T) {
+	rules, err := Compile([]config.RuleConfig{{
+		Name: "by-year",
+		When: []config.ConditionConfig{{Field: "title", Pattern: ".*"}},
+		Then: config.ActionConfig{DocumentType: "Archive {{year}}"},
+	}})
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+
+	action, _, err := rules[0].Match(Document{Title: "x", CreatedDate: "2024-01-01"}, nil)
+	if err != nil {
+		t.Fatalf("Match: %v", err)
+	}
+	if action.DocumentType != "Archive 2024" {
+		t.Errorf("DocumentType = %q, want Archive 2024", action.DocumentType)
+	}
+}
+
+func TestRule_Match_LLMPlaceholder(t *testing.T) {
+	rules, err := Compile([]config.RuleConfig{{
+		Name: "classified",
+		When: []config.ConditionConfig{{Field: "title", Pattern: ".*"}},
+		Then: config.ActionConfig{Correspondent: `{{llm:"who sent this?"}}`},
+	}})
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+
+	action, _, err := rules[0].Match(Document{Title: "x"}, &stubClassifier{answer: "Acme"})
+	if err != nil {
+		t.Fatalf("Match: %v", err)
+	}
+	if action.Correspondent != "Acme" {
+		t.Errorf("Correspondent = %q, want Acme", action.Correspondent)
+	}
+
+	if _, _, err := rules[0].Match(Document{Title: "x"}, nil); err == nil {
+		t.Error("expected an error when no classifier is configured")
+	}
+
+	if _, _, err := rules[0].Match(Document{Title: "x"}, &stubClassifier{failWith: errors.New("boom")}); err == nil {
+		t.Error("expected the classifier's error to propagate")
+	}
+}
+
+func TestRule_Match_UnknownPlaceholderErrors(t *testing.T) {
+	rules, err := Compile([]config.RuleConfig{{
+		Name: "bad-template",
+		When: []config.ConditionConfig{{Field: "title", Pattern: ".*"}},
+		Then: config.ActionConfig{Correspondent: "{{nonsense}}"},
+	}})
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+
+	if _, _, err := rules[0].Match(Document{Title: "x"}, nil); err == nil {
+		t.Error("expected an error for an unknown placeholder")
+	}
+}
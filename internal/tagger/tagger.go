@@ -0,0 +1,145 @@
+// Package tagger implements embeddings-based auto-tagging: each tag is
+// represented by a centroid vector averaged from example texts, and a
+// document is tagged with whatever centroids its content embedding is
+// cosine-similar enough to. The centroids are persisted as a gob-encoded
+// map so the store survives process restarts without a database.
+package tagger
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Embedder computes a vector embedding for a piece of text. internal.LLMClient
+// satisfies this via its Embed method; it is expressed as an interface here
+// so the tagger package doesn't need to import internal.
+type Embedder interface {
+	Embed(ctx context.Context, text string) ([]float32, error)
+}
+
+// Store holds tag centroids and persists them to a gob file at path.
+type Store struct {
+	path string
+
+	mu        sync.Mutex
+	centroids map[string][]float32
+}
+
+// NewStore loads the centroid file at path if it exists, or starts with an
+// empty store otherwise.
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path, centroids: make(map[string][]float32)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tag store: %w", err)
+	}
+
+	dec := gob.NewDecoder(bytes.NewReader(data))
+	if err := dec.Decode(&s.centroids); err != nil {
+		return nil, fmt.Errorf("failed to decode tag store: %w", err)
+	}
+	return s, nil
+}
+
+// Save writes the current centroids to s.path, creating parent directories
+// as needed.
+func (s *Store) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create tag store directory: %w", err)
+	}
+
+	f, err := os.Create(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to create tag store file: %w", err)
+	}
+	defer f.Close()
+
+	if err := gob.NewEncoder(f).Encode(s.centroids); err != nil {
+		return fmt.Errorf("failed to encode tag store: %w", err)
+	}
+	return nil
+}
+
+// TrainTag computes the embedding of each example text and stores the
+// average as tagName's centroid, overwriting any previous centroid.
+func (s *Store) TrainTag(ctx context.Context, embedder Embedder, tagName string, exampleTexts []string) error {
+	if len(exampleTexts) == 0 {
+		return fmt.Errorf("no example texts provided for tag %q", tagName)
+	}
+
+	var sum []float32
+	for _, text := range exampleTexts {
+		vec, err := embedder.Embed(ctx, text)
+		if err != nil {
+			return fmt.Errorf("failed to embed example for tag %q: %w", tagName, err)
+		}
+		if sum == nil {
+			sum = make([]float32, len(vec))
+		}
+		for i, v := range vec {
+			sum[i] += v
+		}
+	}
+
+	centroid := make([]float32, len(sum))
+	for i, v := range sum {
+		centroid[i] = v / float32(len(exampleTexts))
+	}
+
+	s.mu.Lock()
+	s.centroids[tagName] = centroid
+	s.mu.Unlock()
+
+	return nil
+}
+
+// TagsForContent embeds content (the caller is responsible for truncating
+// it to a reasonable length) and returns every tag whose centroid's cosine
+// similarity to that embedding meets or exceeds threshold.
+func (s *Store) TagsForContent(ctx context.Context, embedder Embedder, content string, threshold float64) ([]string, error) {
+	vec, err := embedder.Embed(ctx, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed content: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var tags []string
+	for tagName, centroid := range s.centroids {
+		if cosineSimilarity(vec, centroid) >= threshold {
+			tags = append(tags, tagName)
+		}
+	}
+	return tags, nil
+}
+
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,116 @@
+package tagger
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// fakeEmbedder maps each known text to a fixed vector so tests don't need a
+// real LLM backend; Embed returns an error for any text not in vectors.
+type fakeEmbedder struct {
+	vectors map[string][]float32
+}
+
+func (f *fakeEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	v, ok := f.vectors[text]
+	if !ok {
+		return nil, errors.New("no fake vector for text")
+	}
+	return v, nil
+}
+
+func TestStore_TrainTag_AveragesExamples(t *testing.T) {
+	embedder := &fakeEmbedder{vectors: map[string][]float32{
+		"invoice one": {1, 0},
+		"invoice two": {0, 1},
+	}}
+	store, err := NewStore(filepath.Join(t.TempDir(), "centroids.gob"))
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+
+	if err := store.TrainTag(context.Background(), embedder, "invoice", []string{"invoice one", "invoice two"}); err != nil {
+		t.Fatalf("TrainTag: %v", err)
+	}
+
+	got := store.centroids["invoice"]
+	want := []float32{0.5, 0.5}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("centroid = %v, want %v", got, want)
+	}
+}
+
+func TestStore_TrainTag_NoExamples(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "centroids.gob"))
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	if err := store.TrainTag(context.Background(), &fakeEmbedder{}, "invoice", nil); err == nil {
+		t.Error("expected an error for zero example texts, got nil")
+	}
+}
+
+func TestStore_TagsForContent_ThresholdFiltering(t *testing.T) {
+	embedder := &fakeEmbedder{vectors: map[string][]float32{
+		"query": {1, 0},
+	}}
+	store, err := NewStore(filepath.Join(t.TempDir(), "centroids.gob"))
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	store.centroids["exact"] = []float32{1, 0}
+	store.centroids["orthogonal"] = []float32{0, 1}
+
+	tags, err := store.TagsForContent(context.Background(), embedder, "query", 0.9)
+	if err != nil {
+		t.Fatalf("TagsForContent: %v", err)
+	}
+	if len(tags) != 1 || tags[0] != "exact" {
+		t.Errorf("tags = %v, want [exact]", tags)
+	}
+}
+
+func TestStore_SaveAndReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "centroids.gob")
+	store, err := NewStore(path)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	store.centroids["invoice"] = []float32{0.1, 0.2, 0.3}
+
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	reloaded, err := NewStore(path)
+	if err != nil {
+		t.Fatalf("NewStore (reload): %v", err)
+	}
+	got := reloaded.centroids["invoice"]
+	if len(got) != 3 || got[0] != 0.1 || got[1] != 0.2 || got[2] != 0.3 {
+		t.Errorf("reloaded centroid = %v, want [0.1 0.2 0.3]", got)
+	}
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float32
+		want float64
+	}{
+		{"identical", []float32{1, 0}, []float32{1, 0}, 1},
+		{"orthogonal", []float32{1, 0}, []float32{0, 1}, 0},
+		{"opposite", []float32{1, 0}, []float32{-1, 0}, -1},
+		{"mismatched lengths", []float32{1, 0}, []float32{1}, 0},
+		{"zero vector", []float32{0, 0}, []float32{1, 0}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cosineSimilarity(tt.a, tt.b); got != tt.want {
+				t.Errorf("cosineSimilarity(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
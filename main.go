@@ -1,17 +1,33 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strings"
+	"syscall"
 
 	"github.com/dhcgn/paperless-ngx-privatemode-ai/config"
 	"github.com/dhcgn/paperless-ngx-privatemode-ai/internal"
+	"github.com/dhcgn/paperless-ngx-privatemode-ai/internal/cache"
 	"github.com/dhcgn/paperless-ngx-privatemode-ai/processor"
 	"github.com/pterm/pterm"
 )
 
+// Exit codes reported by a --non-interactive run, derived from the
+// ActionExecutor's ProgressStats counts after Execute returns (see
+// App.exitCode). Interactive runs keep the plain 0/1 pterm.Error.Println +
+// os.Exit(1) convention in main(), since a human is watching the output.
+const (
+	exitAllSuccess   = 0
+	exitAppError     = 1
+	exitPartialError = 2
+	exitAllFailed    = 3
+)
+
 // Set in build time
 var (
 	Version   string = "dev"
@@ -23,8 +39,28 @@ func main() {
 	// Parse command line arguments
 	configPath := flag.String("config", "", "Path to configuration file")
 	autoSetTitles := flag.Bool("auto-set-titles-for-documents-from-pattern", false, "Automatically set titles for documents matching the configured pattern and exit")
+	serve := flag.Bool("serve", false, "Start a long-running webhook server instead of running a one-shot action")
+	noCache := flag.Bool("no-cache", false, "Disable the persistent OCR/title-generation result cache for this run")
+	cachePrune := flag.Bool("cache-prune", false, "Delete every entry from the result cache and exit")
+	resume := flag.String("resume", "", "Path to a checkpoint file from an interrupted run; already-processed documents are skipped. If omitted, a matching checkpoint under ~/.paperless-ngx-privatemode-ai/runs is offered automatically")
+	resumeErrorsOnly := flag.Bool("resume-errors-only", false, "With --resume (or an auto-discovered checkpoint), only retry documents the prior run recorded as errors; still skip its successes and skips")
+	nonInteractive := flag.Bool("non-interactive", false, "Auto-accept prompts instead of blocking on stdin, for cron/systemd/CI use")
+	output := flag.String("output", "text", "Output format: 'text' for pterm widgets, 'json' for a newline-delimited JSON event stream on stdout (implies --non-interactive)")
+	dryRun := flag.Bool("dry-run", false, "Decide titles/content without writing them to Paperless; print a unified diff preview and write a .diff.jsonl report instead")
+	concurrency := flag.Int("concurrency", 0, "Worker count for every download/render/LLM stage whose own count config.yaml leaves unset (0 keeps config.yaml's max_parallel and the per-stage defaults)")
+	noPager := flag.Bool("no-pager", false, "Don't page the filtered document list before a SetTitleAction/SetOcrInContentAction confirmation prompt, even on a tall terminal")
 	flag.Parse()
 
+	jsonOutput := *output == "json"
+	if jsonOutput {
+		// The JSON event stream is the only thing allowed on stdout in this
+		// mode, so redirect pterm's human-oriented widgets to stderr.
+		pterm.SetDefaultOutput(os.Stderr)
+	} else if *output != "text" {
+		pterm.Error.Printf("Invalid --output value %q, must be 'text' or 'json'\n", *output)
+		os.Exit(1)
+	}
+
 	// Show banner
 	showBanner()
 
@@ -37,12 +73,26 @@ func main() {
 
 	// Initialize application
 	app := &App{
-		ConfigPath:    *configPath,
-		AutoSetTitles: *autoSetTitles,
+		ConfigPath:       *configPath,
+		AutoSetTitles:    *autoSetTitles,
+		Serve:            *serve,
+		NoCache:          *noCache,
+		CachePrune:       *cachePrune,
+		Resume:           *resume,
+		ResumeErrorsOnly: *resumeErrorsOnly,
+		NonInteractive:   *nonInteractive || jsonOutput,
+		JSONOutput:       jsonOutput,
+		DryRun:           *dryRun,
+		Concurrency:      *concurrency,
+		NoPager:          *noPager,
 	}
 
 	// Run the application following the program flow
-	if err := app.Run(); err != nil {
+	err := app.Run()
+	if app.NonInteractive {
+		os.Exit(app.exitCode(err))
+	}
+	if err != nil {
 		pterm.Error.Printf("Application failed: %v\n", err)
 		os.Exit(1)
 	}
@@ -63,6 +113,39 @@ type App struct {
 	ConfigPath    string
 	Config        *config.Config
 	AutoSetTitles bool
+	Serve         bool
+	NoCache       bool
+	CachePrune    bool
+	// Resume is the path to a checkpoint file written by a prior, interrupted
+	// run (see App.writeCheckpoint); already-processed documents are skipped
+	// via ActionExecutor.SetResumeIDs.
+	Resume string
+	// ResumeErrorsOnly, combined with Resume (explicit or auto-discovered),
+	// retries only the documents the prior run recorded as errors, still
+	// skipping its successes and skips (see internal.Checkpoint.IDsWithStatus).
+	ResumeErrorsOnly bool
+	// NonInteractive suppresses prompts that would otherwise block on
+	// stdin, auto-accepting the default choice instead (see
+	// ActionExecutor.SetNonInteractive). Implied by JSONOutput.
+	NonInteractive bool
+	// JSONOutput streams newline-delimited JSON events to stdout instead of
+	// pterm widgets (see ActionExecutor.SetJSONOutput); pterm's own output
+	// has already been redirected to stderr by main().
+	JSONOutput bool
+	// DryRun decides titles/content without writing them to Paperless (see
+	// ActionExecutor.SetDryRun); Run writes the decided changes to a
+	// ".diff.jsonl" report next to the config file afterwards.
+	DryRun bool
+	// Concurrency overrides the download/render/LLM worker counts uniformly
+	// (see ActionExecutor.SetConcurrency). 0 leaves config.yaml's per-stage
+	// settings, or their built-in defaults, untouched.
+	Concurrency int
+	// NoPager disables maybePageFilteredDocuments regardless of terminal
+	// size (see ActionExecutor.SetNoPager).
+	NoPager bool
+	// executor is kept around so exitCode can read its outcome counts after
+	// Run returns.
+	executor *processor.ActionExecutor
 }
 
 func (a *App) Run() error {
@@ -82,10 +165,27 @@ func (a *App) Run() error {
 	}
 	pterm.Success.Println("Configuration is valid")
 
+	if a.NoCache {
+		a.Config.Processing.Cache.Disabled = true
+	}
+
+	// 2.5. Cache maintenance doesn't need Paperless or LLM connectivity, so
+	// handle it and exit before the checks below.
+	if a.CachePrune {
+		return pruneCache(a.Config)
+	}
+
+	// Install a Ctrl-C/SIGTERM handler so a long batch run (case 7 below)
+	// finishes the document it's on, writes a resume checkpoint, and exits
+	// cleanly instead of being killed mid-document. It's installed before
+	// the connectivity checks below so Ctrl-C can abort those too.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// 3. Check if paperless-ngx is accessible
 	pterm.Info.Println("Checking Paperless NGX accessibility...")
 	paperlessClient := internal.NewPaperlessClient(a.Config)
-	if err := paperlessClient.CheckConnection(); err != nil {
+	if err := paperlessClient.CheckConnection(ctx); err != nil {
 		return fmt.Errorf("paperless-ngx is not accessible: %w", err)
 	}
 	pterm.Success.Println("Paperless NGX is accessible")
@@ -93,21 +193,174 @@ func (a *App) Run() error {
 	// 4. Check if privatemode.ai is accessible and models are available
 	pterm.Info.Println("Checking LLM service accessibility...")
 	llmClient := internal.NewLLMClient(a.Config)
-	if err := llmClient.CheckConnection(); err != nil {
+	if err := llmClient.CheckConnection(ctx); err != nil {
 		return fmt.Errorf("LLM service is not accessible: %w", err)
 	}
 	pterm.Success.Println("LLM service is accessible")
 
-	// 5. Ask user for action
+	// 5. In serve mode, start the webhook server and run until it exits.
+	if a.Serve {
+		addr := a.Config.Server.Addr
+		if addr == "" {
+			addr = ":8085"
+		}
+		executor := processor.NewActionExecutor(ctx, paperlessClient, llmClient, a.Config, true)
+		server := processor.NewWebhookServer(executor, a.Config.Server.Workers)
+		return server.Serve(addr)
+	}
+
+	// 6. Ask user for action
 	action, autonomous, err := a.askUserForAction()
 	if err != nil {
 		return fmt.Errorf("failed to get user action: %w", err)
 	}
 
-	// 6. Execute action and show progress
+	// 7. Execute action and show progress
 	pterm.Info.Printf("Executing action: %s\n", action.Description())
-	executor := processor.NewActionExecutor(paperlessClient, llmClient, a.Config, autonomous)
-	return executor.Execute(action)
+	executor := processor.NewActionExecutor(ctx, paperlessClient, llmClient, a.Config, autonomous)
+	executor.SetNonInteractive(a.NonInteractive)
+	executor.SetJSONOutput(a.JSONOutput)
+	executor.SetDryRun(a.DryRun)
+	executor.SetConcurrency(a.Concurrency)
+	executor.SetNoPager(a.NoPager)
+	a.executor = executor
+
+	filterHash := internal.HashFilterParams(a.Config.Filters.Title, a.Config.Filters.Content, a.Config.Filters.Semantic)
+
+	resumePath := a.Resume
+	if resumePath == "" && !a.NonInteractive {
+		// No explicit --resume given; offer an interrupted run of the same
+		// action found under DefaultCheckpointDir instead of requiring the
+		// user to remember its path.
+		if dir, dirErr := internal.DefaultCheckpointDir(); dirErr == nil {
+			if found, cp, ok, findErr := internal.FindLatestCheckpoint(dir, action.Description()); findErr == nil && ok {
+				confirmed, confirmErr := pterm.DefaultInteractiveConfirm.
+					WithDefaultValue(false).
+					WithDefaultText(fmt.Sprintf("Found an interrupted run of this action (%d processed, %d errors) at %s. Resume it?", len(cp.ProcessedIDs), cp.Errors, found)).
+					Show()
+				if confirmErr == nil && confirmed {
+					resumePath = found
+				}
+			}
+		}
+	}
+
+	checkpointTarget := resumePath
+	if resumePath != "" {
+		cp, err := internal.LoadCheckpoint(resumePath)
+		if err != nil {
+			return fmt.Errorf("failed to load resume checkpoint: %w", err)
+		}
+		if cp.FilterHash != "" && cp.FilterHash != filterHash {
+			pterm.Warning.Println("Resume checkpoint was written with different filter settings; resumed documents may not match this run's filters")
+		}
+		resumeIDs := cp.ProcessedIDs
+		if a.ResumeErrorsOnly {
+			resumeIDs = append(cp.IDsWithStatus("success"), cp.IDsWithStatus("skipped")...)
+		}
+		pterm.Info.Printf("Resuming: skipping %d document(s) already processed\n", len(resumeIDs))
+		executor.SetResumeIDs(resumeIDs)
+	} else {
+		dir, dirErr := internal.DefaultCheckpointDir()
+		if dirErr == nil {
+			checkpointTarget = internal.NewCheckpointPath(dir, action.Description())
+		}
+	}
+	executor.SetCheckpointTarget(checkpointTarget, action.Description(), filterHash)
+
+	err = executor.Execute(action)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		pterm.Warning.Println("Interrupted, writing resume checkpoint...")
+		if cpErr := a.writeCheckpoint(executor); cpErr != nil {
+			pterm.Error.Printf("Failed to write resume checkpoint: %v\n", cpErr)
+		}
+	}
+	if a.DryRun {
+		if drErr := a.writeDiffReport(executor); drErr != nil {
+			pterm.Error.Printf("Failed to write diff report: %v\n", drErr)
+		}
+	}
+	return err
+}
+
+// exitCode derives a process exit code from runErr and the executor's
+// outcome counts, for a --non-interactive / --output=json run to be
+// composable with scripts that branch on $?.
+func (a *App) exitCode(runErr error) int {
+	if runErr != nil {
+		return exitAppError
+	}
+	if a.executor == nil {
+		return exitAllSuccess
+	}
+
+	total, success, errors, _ := a.executor.Counts()
+	switch {
+	case total == 0 || errors == 0:
+		return exitAllSuccess
+	case success == 0:
+		return exitAllFailed
+	default:
+		return exitPartialError
+	}
+}
+
+// writeCheckpoint persists executor's progress so far to a JSON file next
+// to the config file, for a later run started with --resume to pick up
+// from.
+func (a *App) writeCheckpoint(executor *processor.ActionExecutor) error {
+	path := checkpointPath(a.ConfigPath)
+	if err := internal.SaveCheckpoint(path, executor.Checkpoint()); err != nil {
+		return err
+	}
+	pterm.Info.Printf("Wrote resume checkpoint to %s\n", path)
+	return nil
+}
+
+// checkpointPath derives the checkpoint file path from configPath, e.g.
+// "config.yaml" -> "config.checkpoint.json".
+func checkpointPath(configPath string) string {
+	dir := filepath.Dir(configPath)
+	base := strings.TrimSuffix(filepath.Base(configPath), filepath.Ext(configPath))
+	return filepath.Join(dir, base+".checkpoint.json")
+}
+
+// writeDiffReport persists every title/content change executor decided
+// during a --dry-run run to a JSON Lines file next to the config file, for
+// review or later replay by a plan-applying action before running for real.
+func (a *App) writeDiffReport(executor *processor.ActionExecutor) error {
+	path := diffReportPath(a.ConfigPath)
+	if err := internal.SaveDiffReport(path, executor.Changes()); err != nil {
+		return err
+	}
+	pterm.Info.Printf("Wrote diff report to %s\n", path)
+	return nil
+}
+
+// diffReportPath derives the diff report file path from configPath, e.g.
+// "config.yaml" -> "config.diff.jsonl".
+func diffReportPath(configPath string) string {
+	dir := filepath.Dir(configPath)
+	base := strings.TrimSuffix(filepath.Base(configPath), filepath.Ext(configPath))
+	return filepath.Join(dir, base+".diff.jsonl")
+}
+
+// pruneCache deletes every entry from the result cache configured by
+// config.Processing.Cache and reports how many were removed.
+func pruneCache(config *config.Config) error {
+	pterm.Info.Println("Pruning result cache...")
+	store, err := cache.New(config.Processing.Cache)
+	if err != nil {
+		return fmt.Errorf("failed to open result cache: %w", err)
+	}
+	defer store.Close()
+
+	n, err := store.Prune()
+	if err != nil {
+		return fmt.Errorf("failed to prune result cache: %w", err)
+	}
+	pterm.Success.Printf("Pruned %d cache entries\n", n)
+	return nil
 }
 
 func (a *App) askUserForAction() (processor.Action, bool, error) {
@@ -126,6 +379,9 @@ func (a *App) askUserForAction() (processor.Action, bool, error) {
 	options := []string{
 		fmt.Sprintf("Set titles from documents with pattern: '%s'", patternTitleJoined),
 		fmt.Sprintf("Set content with OCR from documents with pattern: '%s'", patternOcrJoined),
+		fmt.Sprintf("Apply routing rules (%d configured)", len(a.Config.Rules.Rules)),
+		"Extract structured metadata (correspondent, date, amount, ...)",
+		"Classify documents (tags, correspondent, document type) with the LLM",
 		"Exit",
 	}
 
@@ -144,6 +400,12 @@ func (a *App) askUserForAction() (processor.Action, bool, error) {
 	case options[1]:
 		return &processor.SetOcrInContentAction{}, false, nil
 	case options[2]:
+		return &processor.ApplyRulesAction{}, false, nil
+	case options[3]:
+		return &processor.ExtractMetadataAction{}, false, nil
+	case options[4]:
+		return &processor.ClassifyDocumentsAction{}, false, nil
+	case options[5]:
 		pterm.Info.Println("Exiting...")
 		os.Exit(0)
 		return nil, false, nil
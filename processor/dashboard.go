@@ -0,0 +1,108 @@
+package processor
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/pterm/pterm"
+)
+
+// stageActivity tracks how many jobs are in flight in each stage of a
+// pipeline (processor/pipeline.go), for the live stageDashboard. Workers
+// call the matching inc before starting a unit of work and dec when it
+// finishes.
+type stageActivity struct {
+	download int64
+	render   int64
+	title    int64
+}
+
+func (a *stageActivity) incDownload() { atomic.AddInt64(&a.download, 1) }
+func (a *stageActivity) decDownload() { atomic.AddInt64(&a.download, -1) }
+func (a *stageActivity) incRender()   { atomic.AddInt64(&a.render, 1) }
+func (a *stageActivity) decRender()   { atomic.AddInt64(&a.render, -1) }
+func (a *stageActivity) incTitle()    { atomic.AddInt64(&a.title, 1) }
+func (a *stageActivity) decTitle()    { atomic.AddInt64(&a.title, -1) }
+
+// stageGauge pairs a dashboard line's label with the live count it reports.
+type stageGauge struct {
+	label string
+	count func() int64
+}
+
+// stageDashboard renders one live line per pipeline stage (how many jobs
+// are in flight right now) plus an aggregate line, via
+// pterm.DefaultMultiPrinter. It's only used for interactive runs (see
+// processOCRGeneration and processDocumentsForTitleGeneration): an
+// autonomous or webhook-driven run has no one watching the terminal, so
+// those keep the simpler ticking bar chart startProgressReporter already
+// prints.
+type stageDashboard struct {
+	multi   pterm.MultiPrinter
+	gauges  []stageGauge
+	lines   []*pterm.SpinnerPrinter
+	overall *pterm.SpinnerPrinter
+	stats   *ProgressStats
+	done    chan struct{}
+}
+
+// newStageDashboard starts the live display. gauges determines how many
+// per-stage lines are shown and what each one reads from.
+func newStageDashboard(gauges []stageGauge, stats *ProgressStats) (*stageDashboard, error) {
+	multi := pterm.DefaultMultiPrinter
+
+	d := &stageDashboard{multi: multi, gauges: gauges, stats: stats, done: make(chan struct{})}
+	for _, g := range gauges {
+		line, err := pterm.DefaultSpinner.WithWriter(multi.NewWriter()).Start(g.label)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start dashboard line %q: %w", g.label, err)
+		}
+		d.lines = append(d.lines, line)
+	}
+
+	overall, err := pterm.DefaultSpinner.WithWriter(multi.NewWriter()).Start("overall: starting...")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start dashboard overall line: %w", err)
+	}
+	d.overall = overall
+
+	if _, err := multi.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start dashboard: %w", err)
+	}
+
+	go d.run()
+	return d, nil
+}
+
+func (d *stageDashboard) run() {
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			d.render()
+		case <-d.done:
+			return
+		}
+	}
+}
+
+func (d *stageDashboard) render() {
+	for i, g := range d.gauges {
+		d.lines[i].UpdateText(fmt.Sprintf("%s: %d in flight", g.label, g.count()))
+	}
+	total, processed, success, errors, skipped := d.stats.snapshot()
+	d.overall.UpdateText(fmt.Sprintf("overall: %d/%d processed (success %d, errors %d, skipped %d)", processed, total, success, errors, skipped))
+}
+
+// stop renders a final frame and ends the live display.
+func (d *stageDashboard) stop() {
+	close(d.done)
+	d.render()
+	for _, line := range d.lines {
+		line.Stop()
+	}
+	d.overall.Stop()
+	d.multi.Stop()
+}
@@ -1,6 +1,7 @@
 package processor
 
 import (
+	"context"
 	"errors"
 
 	"github.com/dhcgn/paperless-ngx-privatemode-ai/internal"
@@ -8,16 +9,17 @@ import (
 
 type Paperless interface {
 	GetPaperlessClient() *internal.PaperlessClient
+	IsDryRun() bool
 }
 
-func (clients ActionExecutor) GetPaperlessClient() *internal.PaperlessClient {
+func (clients *ActionExecutor) GetPaperlessClient() *internal.PaperlessClient {
 	if clients.paperlessClient == nil {
 		return nil
 	}
 	return clients.paperlessClient
 }
 
-func SetContentOfPaperlessDocument(instances Paperless, documentID int, content string) error {
+func SetContentOfPaperlessDocument(ctx context.Context, instances Paperless, documentID int, content string) error {
 	if documentID <= 0 {
 		return errors.New("invalid document ID")
 	}
@@ -26,19 +28,25 @@ func SetContentOfPaperlessDocument(instances Paperless, documentID int, content
 		return errors.New("content cannot be empty")
 	}
 
+	// --dry-run: record the intent (handled by the caller, which has the
+	// old value) without touching the Paperless API.
+	if instances.IsDryRun() {
+		return nil
+	}
+
 	// Update document content
 	updates := map[string]interface{}{
 		"content": content,
 	}
 
-	err := instances.GetPaperlessClient().UpdateDocument(documentID, updates)
+	err := instances.GetPaperlessClient().UpdateDocument(ctx, documentID, updates)
 	if err != nil {
 		return errors.Join(err, errors.New("failed to set document content"))
 	}
 	return nil
 }
 
-func SetTitleOfPaperlessDocument(instances Paperless, documentID int, title string) error {
+func SetTitleOfPaperlessDocument(ctx context.Context, instances Paperless, documentID int, title string) error {
 	if documentID <= 0 {
 		return errors.New("invalid document ID")
 	}
@@ -47,19 +55,100 @@ func SetTitleOfPaperlessDocument(instances Paperless, documentID int, title stri
 		return errors.New("title cannot be empty")
 	}
 
+	// --dry-run: record the intent (handled by the caller, which has the
+	// old value) without touching the Paperless API.
+	if instances.IsDryRun() {
+		return nil
+	}
+
 	// Update document content
 	updates := map[string]interface{}{
 		"title": title,
 	}
 
-	err := instances.GetPaperlessClient().UpdateDocument(documentID, updates)
+	err := instances.GetPaperlessClient().UpdateDocument(ctx, documentID, updates)
 	if err != nil {
 		return errors.Join(err, errors.New("failed to set document title"))
 	}
 	return nil
 }
 
-func (clients ActionExecutor) OcrPaperlessDocument(documentID int, statusCallback func(string)) (string, *internal.CaptionResponse, error) {
+// SetTagsOfPaperlessDocument PATCHes documentID's tags to tagIDs, replacing
+// whatever tags it already had - same dry-run short-circuit as
+// SetTitleOfPaperlessDocument/SetContentOfPaperlessDocument.
+func SetTagsOfPaperlessDocument(ctx context.Context, instances Paperless, documentID int, tagIDs []int) error {
+	if documentID <= 0 {
+		return errors.New("invalid document ID")
+	}
+
+	if instances.IsDryRun() {
+		return nil
+	}
+
+	updates := map[string]interface{}{
+		"tags": tagIDs,
+	}
+
+	err := instances.GetPaperlessClient().UpdateDocument(ctx, documentID, updates)
+	if err != nil {
+		return errors.Join(err, errors.New("failed to set document tags"))
+	}
+	return nil
+}
+
+// SetCorrespondentOfPaperlessDocument PATCHes documentID's correspondent to
+// correspondentID.
+func SetCorrespondentOfPaperlessDocument(ctx context.Context, instances Paperless, documentID int, correspondentID int) error {
+	if documentID <= 0 {
+		return errors.New("invalid document ID")
+	}
+
+	if correspondentID <= 0 {
+		return errors.New("invalid correspondent ID")
+	}
+
+	if instances.IsDryRun() {
+		return nil
+	}
+
+	updates := map[string]interface{}{
+		"correspondent": correspondentID,
+	}
+
+	err := instances.GetPaperlessClient().UpdateDocument(ctx, documentID, updates)
+	if err != nil {
+		return errors.Join(err, errors.New("failed to set document correspondent"))
+	}
+	return nil
+}
+
+// SetDocumentTypeOfPaperlessDocument PATCHes documentID's document type to
+// documentTypeID.
+func SetDocumentTypeOfPaperlessDocument(ctx context.Context, instances Paperless, documentID int, documentTypeID int) error {
+	if documentID <= 0 {
+		return errors.New("invalid document ID")
+	}
+
+	if documentTypeID <= 0 {
+		return errors.New("invalid document type ID")
+	}
+
+	if instances.IsDryRun() {
+		return nil
+	}
+
+	updates := map[string]interface{}{
+		"document_type": documentTypeID,
+	}
+
+	err := instances.GetPaperlessClient().UpdateDocument(ctx, documentID, updates)
+	if err != nil {
+		return errors.Join(err, errors.New("failed to set document type"))
+	}
+	return nil
+}
+
+func (clients *ActionExecutor) OcrPaperlessDocument(documentID int, statusCallback func(string)) (string, *internal.CaptionResponse, error) {
 	if documentID <= 0 {
 		return "", nil, errors.New("invalid document ID")
 	}
@@ -70,26 +159,23 @@ func (clients ActionExecutor) OcrPaperlessDocument(documentID int, statusCallbac
 		return "", nil, errors.New("config not initialized")
 	}
 
-	if statusCallback != nil {
-		statusCallback("Downloading PDF document...")
-	}
-	pdfBytes, err := clients.paperlessClient.DownloadDocument(documentID)
-	if err != nil {
-		return "", nil, errors.Join(err, errors.New("failed to download PDF"))
+	ctx := clients.ctx
+	if ctx == nil {
+		ctx = context.Background()
 	}
 
 	if statusCallback != nil {
-		statusCallback("Converting PDF to JPEG...")
+		statusCallback("Downloading PDF document...")
 	}
-	jpegData, err := internal.RenderPageToJpg(clients.config, pdfBytes, 0)
+	pdfBytes, err := clients.paperlessClient.DownloadDocument(ctx, documentID)
 	if err != nil {
-		return "", nil, errors.Join(err, errors.New("failed to render page to JPG"))
+		return "", nil, errors.Join(err, errors.New("failed to download PDF"))
 	}
 
 	if statusCallback != nil {
-		statusCallback("Making OCR from JPEG...")
+		statusCallback("Rendering and OCRing every page...")
 	}
-	newContent, err := clients.llmClient.MakeOcr(jpegData)
+	newContent, err := NewOcrPipeline(clients).Run(ctx, documentID, pdfBytes)
 	if err != nil {
 		return "", nil, errors.Join(err, errors.New("failed to make OCR"))
 	}
@@ -97,7 +183,7 @@ func (clients ActionExecutor) OcrPaperlessDocument(documentID int, statusCallbac
 	if statusCallback != nil {
 		statusCallback("Generating title from content...")
 	}
-	captions, err := clients.llmClient.GenerateTitleFromContent(newContent)
+	captions, err := clients.llmClient.GenerateTitleFromContent(ctx, documentID, newContent)
 	if err != nil {
 		return "", nil, errors.Join(err, errors.New("failed to generate title"))
 	}
@@ -0,0 +1,231 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/dhcgn/paperless-ngx-privatemode-ai/internal"
+	"github.com/dhcgn/paperless-ngx-privatemode-ai/internal/render"
+	"github.com/pterm/pterm"
+)
+
+// OcrPipeline runs the full-book OCR flow: render and OCR every page of a
+// PDF (not just page 0, like the original single-page SetOcrInContentAction
+// path), optionally trying a few binarization thresholds per page and
+// keeping whichever OCRs best. It's used both by the concurrent
+// processOCRGeneration pipeline (processor/pipeline.go) and by
+// headless_actions.go's single-document webhook path.
+type OcrPipeline struct {
+	executor *ActionExecutor
+}
+
+// NewOcrPipeline builds an OcrPipeline backed by executor's clients and
+// config.
+func NewOcrPipeline(executor *ActionExecutor) *OcrPipeline {
+	return &OcrPipeline{executor: executor}
+}
+
+// binarizationThresholds are the fixed thresholds tried per page when
+// config.Processing.OCR.Preprocess is enabled. See render.Binarize for why
+// this is a global-threshold approximation of Sauvola binarization rather
+// than the real thing.
+var binarizationThresholds = []float64{0.1, 0.2, 0.3}
+
+// defaultOCRPageWorkers is the page-level concurrency used when
+// config.Processing.OCR.Concurrency is unset. It's deliberately small: pages
+// of the same document usually share a render backend process and an LLM
+// rate limit, so this bounds fan-out within one document the way
+// processing.workers.render/llm bound fan-out across documents.
+const defaultOCRPageWorkers = 4
+
+// PageOCR is one page's OCR result. DurationMs and TokensUsed are
+// best-effort instrumentation so a caller can persist per-page metadata and
+// later re-process just the pages that were slow or expensive; TokensUsed is
+// a before/after reading of the client's token ledger (see
+// LLMClient.TokensForDocument) since Backend.OCR itself returns no Usage
+// block to its caller.
+type PageOCR struct {
+	PageNum    int
+	Text       string
+	TokensUsed int
+	DurationMs int64
+}
+
+// RunPagesDetailed renders and OCRs every page of pdfBytes, dispatching up to
+// processing.ocr.concurrency pages at a time (defaultOCRPageWorkers if
+// unset) through a bounded worker pool, and returns one PageOCR per page in
+// page order. ctx is checked before a worker starts a page, so a cancelled
+// batch run (see main.go's signal.NotifyContext wiring) stops picking up new
+// pages but lets pages already in flight finish.
+func (p *OcrPipeline) RunPagesDetailed(ctx context.Context, documentID int, pdfBytes []byte) ([]PageOCR, error) {
+	cfg := p.executor.config
+
+	pageCount, err := internal.PageCount(ctx, cfg, pdfBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count pages: %w", err)
+	}
+
+	results := make([]PageOCR, pageCount)
+	errs := make([]error, pageCount)
+
+	concurrency := workerCount(cfg.Processing.OCR.Concurrency, defaultOCRPageWorkers)
+	if concurrency > pageCount {
+		concurrency = pageCount
+	}
+
+	pages := make(chan int, pageCount)
+	for page := 0; page < pageCount; page++ {
+		pages <- page
+	}
+	close(pages)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for page := range pages {
+				if err := ctx.Err(); err != nil {
+					errs[page] = err
+					continue
+				}
+
+				start := time.Now()
+				tokensBefore := p.executor.llmClient.TokensForDocument(documentID)
+				text, err := p.ocrPage(ctx, documentID, pdfBytes, page)
+				if err != nil {
+					errs[page] = fmt.Errorf("page %d: %w", page+1, err)
+					continue
+				}
+
+				results[page] = PageOCR{
+					PageNum:    page + 1,
+					Text:       text,
+					TokensUsed: p.executor.llmClient.TokensForDocument(documentID) - tokensBefore,
+					DurationMs: time.Since(start).Milliseconds(),
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// RunPages is RunPagesDetailed with just the per-page text, for callers
+// (like internal.WriteHOCR) that only need the plain strings.
+func (p *OcrPipeline) RunPages(ctx context.Context, documentID int, pdfBytes []byte) ([]string, error) {
+	detailed, err := p.RunPagesDetailed(ctx, documentID, pdfBytes)
+	if err != nil {
+		return nil, err
+	}
+	pages := make([]string, len(detailed))
+	for i, d := range detailed {
+		pages[i] = d.Text
+	}
+	return pages, nil
+}
+
+// Run is RunPagesDetailed, concatenated into the single content string
+// SetContentOfPaperlessDocument expects, with a "--- Page N ---" marker
+// before each page so a later re-run can isolate and re-OCR just one bad
+// page from the content already written back to Paperless.
+func (p *OcrPipeline) Run(ctx context.Context, documentID int, pdfBytes []byte) (string, error) {
+	pages, err := p.RunPagesDetailed(ctx, documentID, pdfBytes)
+	if err != nil {
+		return "", err
+	}
+	return joinPagesWithMarkers(pages), nil
+}
+
+// joinPagesWithMarkers concatenates pages, in page order, into the single
+// content string written back to Paperless.
+func joinPagesWithMarkers(pages []PageOCR) string {
+	parts := make([]string, len(pages))
+	for i, page := range pages {
+		parts[i] = fmt.Sprintf("--- Page %d ---\n\n%s", page.PageNum, page.Text)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func (p *OcrPipeline) ocrPage(ctx context.Context, documentID int, pdfBytes []byte, page int) (string, error) {
+	cfg := p.executor.config
+
+	jpegData, err := internal.RenderPageToJpg(ctx, cfg, pdfBytes, page)
+	if err != nil {
+		return "", fmt.Errorf("failed to render page: %w", err)
+	}
+
+	if !cfg.Processing.OCR.Preprocess {
+		return p.executor.llmClient.MakeOcr(ctx, documentID, pdfBytes, page, jpegData)
+	}
+
+	return p.ocrPageWithBestThreshold(ctx, documentID, page, jpegData)
+}
+
+// ocrPageWithBestThreshold OCRs jpegData at each of binarizationThresholds
+// and keeps the result with the highest ocrConfidence score. These
+// per-threshold trials bypass LLMClient.MakeOcr's cache (via OCRImage)
+// since they aren't the page's single canonical render.
+func (p *OcrPipeline) ocrPageWithBestThreshold(ctx context.Context, documentID, page int, jpegData []byte) (string, error) {
+	var bestText string
+	var bestScore float64
+	var bestThreshold float64
+
+	for i, threshold := range binarizationThresholds {
+		variant, err := render.Binarize(jpegData, threshold, 0)
+		if err != nil {
+			return "", fmt.Errorf("failed to binarize at threshold %.1f: %w", threshold, err)
+		}
+
+		text, err := p.executor.llmClient.OCRImage(ctx, documentID, variant)
+		if err != nil {
+			return "", fmt.Errorf("failed to OCR at threshold %.1f: %w", threshold, err)
+		}
+
+		score := ocrConfidence(text)
+		if i == 0 || score > bestScore {
+			bestText, bestScore, bestThreshold = text, score, threshold
+		}
+	}
+
+	pterm.Debug.Printf("page %d: chose binarization threshold %.1f (heuristic confidence %.2f)\n", page+1, bestThreshold, bestScore)
+	return bestText, nil
+}
+
+// ocrConfidence is a heuristic proxy for OCR quality: the fraction of runes
+// that look like real recognized text (letters, digits, common punctuation,
+// or whitespace) rather than the symbol noise a misread scan tends to
+// produce. MakeOcr/OCRImage return free text with no real confidence score
+// from the model, so this is what the threshold-selection step in
+// ocrPageWithBestThreshold has to work with.
+func ocrConfidence(text string) float64 {
+	if len(text) == 0 {
+		return 0
+	}
+
+	clean := 0
+	total := 0
+	for _, r := range text {
+		total++
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), unicode.IsSpace(r):
+			clean++
+		case strings.ContainsRune(".,;:!?-()/'\"", r):
+			clean++
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(clean) / float64(total)
+}
@@ -0,0 +1,30 @@
+package processor
+
+import "testing"
+
+func TestOcrConfidence(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want float64
+	}{
+		{"empty text", "", 0},
+		{"clean prose", "Hello, world. This is fine.", 1},
+		{"all noise", "#$%^&*@~`", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ocrConfidence(tt.text); got != tt.want {
+				t.Errorf("ocrConfidence(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOcrConfidence_PrefersCleanerText(t *testing.T) {
+	clean := ocrConfidence("This looks like real OCR output.")
+	noisy := ocrConfidence("T#i$ l%%ks ||ke g@rb@ge $$$$$$")
+	if clean <= noisy {
+		t.Errorf("clean confidence %v should exceed noisy confidence %v", clean, noisy)
+	}
+}
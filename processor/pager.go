@@ -0,0 +1,110 @@
+package processor
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dhcgn/paperless-ngx-privatemode-ai/config"
+	"github.com/dhcgn/paperless-ngx-privatemode-ai/internal"
+	"github.com/pterm/pterm"
+	"golang.org/x/term"
+)
+
+// previewContentChars caps how much of a document's content
+// maybePageFilteredDocuments shows per row, for the same reason
+// renderChangesTable caps its own old/new columns: a multi-page OCR'd
+// document's full content would otherwise make a single row span several
+// screen lines.
+const previewContentChars = 80
+
+// maybePageFilteredDocuments shows a filtered document set (ID, current
+// title, URL, first previewContentChars characters of content) through a
+// pager before SetTitleAction/SetOcrInContentAction's confirmation prompt,
+// so the user gets a real look at the filter's selectivity before
+// authorising a bulk LLM run over potentially hundreds of documents. It's a
+// no-op, falling through to the existing bar chart and prompt, when stdout
+// isn't a TTY, the set already fits on one screen, --no-pager
+// (e.SetNoPager) was passed, or no pager binary can be resolved.
+func (e *ActionExecutor) maybePageFilteredDocuments(documents []internal.Document) {
+	if e.noPager || e.autonomous || e.nonInteractive || e.jsonOutput {
+		return
+	}
+
+	fd := int(os.Stdout.Fd())
+	if !term.IsTerminal(fd) {
+		return
+	}
+	_, height, err := term.GetSize(fd)
+	if err != nil || len(documents) <= height {
+		return
+	}
+
+	name, args, ok := resolvePagerCommand(e.config.Pager)
+	if !ok {
+		return
+	}
+
+	if err := runPager(name, args, func(w io.Writer) {
+		writeDocumentPreviewTable(w, documents, e.config)
+	}); err != nil {
+		pterm.Warning.Printf("Failed to page document preview, showing the prompt directly: %v\n", err)
+	}
+}
+
+// resolvePagerCommand picks the pager to pipe the preview table into:
+// cfgPager (Config.Pager) if set, then $PAGER, then "less -R", then no
+// pager at all if none of those resolve to a binary on PATH.
+func resolvePagerCommand(cfgPager string) (name string, args []string, ok bool) {
+	for _, candidate := range []string{cfgPager, os.Getenv("PAGER")} {
+		fields := strings.Fields(candidate)
+		if len(fields) == 0 {
+			continue
+		}
+		if path, err := exec.LookPath(fields[0]); err == nil {
+			return path, fields[1:], true
+		}
+	}
+	if path, err := exec.LookPath("less"); err == nil {
+		return path, []string{"-R"}, true
+	}
+	return "", nil, false
+}
+
+// runPager streams render's output into the pager's stdin through an
+// io.Pipe, rather than buffering the whole preview table first, so a very
+// large filtered set starts displaying as soon as the pager starts.
+func runPager(name string, args []string, render func(io.Writer)) error {
+	r, w := io.Pipe()
+
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = r
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Start(); err != nil {
+		w.Close()
+		return fmt.Errorf("failed to start pager %q: %w", name, err)
+	}
+
+	go func() {
+		render(w)
+		w.Close()
+	}()
+
+	return cmd.Wait()
+}
+
+// writeDocumentPreviewTable renders documents as a tab-aligned table (ID,
+// title, URL, first previewContentChars characters of content) to w.
+func writeDocumentPreviewTable(w io.Writer, documents []internal.Document, cfg *config.Config) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tTITLE\tURL\tCONTENT")
+	for _, doc := range documents {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", doc.ID, doc.Title, cfg.CreateUrl(doc.ID), truncateContext(doc.Content, previewContentChars))
+	}
+	tw.Flush()
+}
@@ -0,0 +1,135 @@
+package processor
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dhcgn/paperless-ngx-privatemode-ai/internal"
+)
+
+// Default worker counts used when config.ProcessingConfig.Workers leaves a
+// stage unset (0).
+const (
+	defaultDownloadWorkers = 4
+	defaultRenderWorkers   = 4
+	defaultLLMWorkers      = 4
+)
+
+// workerCount returns configured if it is a positive number of goroutines,
+// otherwise fallback.
+func workerCount(configured, fallback int) int {
+	if configured <= 0 {
+		return fallback
+	}
+	return configured
+}
+
+// startProgressReporter renders stats on a ticker from a single goroutine so
+// concurrent pipeline stages never race on the terminal. Call the returned
+// stop func once the pipeline has drained to render a final frame and stop
+// the ticker.
+func startProgressReporter(stats *ProgressStats) (stop func()) {
+	done := make(chan struct{})
+	ticker := time.NewTicker(200 * time.Millisecond)
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				stats.renderProgressChart()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+		stats.renderProgressChart()
+	}
+}
+
+// ocrJob carries a single document through the download -> render+OCR ->
+// title stages of processOCRGeneration. Once err is set by a stage, later
+// stages pass the job through unchanged so it still reaches the final,
+// single-threaded update stage where stats and user interaction happen.
+type ocrJob struct {
+	doc      internal.Document
+	pdfBytes []byte
+	pages    []string
+	content  string
+	captions *internal.CaptionResponse
+	err      error
+}
+
+func (e *ActionExecutor) ocrDownloadWorker(in <-chan *ocrJob, out chan<- *ocrJob, wg *sync.WaitGroup, activity *stageActivity) {
+	defer wg.Done()
+	for job := range in {
+		if job.err == nil {
+			activity.incDownload()
+			job.pdfBytes, job.err = e.paperlessClient.DownloadDocument(e.ctx, job.doc.ID)
+			activity.decDownload()
+		}
+		out <- job
+	}
+}
+
+// ocrExtractWorker renders and OCRs every page of job.pdfBytes via
+// OcrPipeline (see processor/ocr_pipeline.go), not just page 0, fanning out
+// across pages as well as across documents, and joins the pages - with
+// "--- Page N ---" markers - into job.content.
+func (e *ActionExecutor) ocrExtractWorker(in <-chan *ocrJob, out chan<- *ocrJob, wg *sync.WaitGroup, activity *stageActivity) {
+	defer wg.Done()
+	pipeline := NewOcrPipeline(e)
+	for job := range in {
+		if job.err == nil {
+			activity.incRender()
+			var detailed []PageOCR
+			detailed, job.err = pipeline.RunPagesDetailed(e.ctx, job.doc.ID, job.pdfBytes)
+			if job.err == nil {
+				job.pages = make([]string, len(detailed))
+				for i, d := range detailed {
+					job.pages[i] = d.Text
+				}
+				job.content = joinPagesWithMarkers(detailed)
+			}
+			activity.decRender()
+		}
+		out <- job
+	}
+}
+
+func (e *ActionExecutor) ocrTitleWorker(in <-chan *ocrJob, out chan<- *ocrJob, wg *sync.WaitGroup, activity *stageActivity) {
+	defer wg.Done()
+	for job := range in {
+		if job.err == nil {
+			activity.incTitle()
+			var captions internal.CaptionResponse
+			captions, job.err = e.llmClient.GenerateTitleFromContent(e.ctx, job.doc.ID, job.content)
+			job.captions = &captions
+			activity.decTitle()
+		}
+		out <- job
+	}
+}
+
+// titleJob carries a single document through the title generation stage of
+// processDocumentsForTitleGeneration.
+type titleJob struct {
+	doc      internal.Document
+	captions *internal.CaptionResponse
+	err      error
+}
+
+func (e *ActionExecutor) titleGenerationWorker(in <-chan *titleJob, out chan<- *titleJob, wg *sync.WaitGroup, activity *stageActivity) {
+	defer wg.Done()
+	for job := range in {
+		activity.incTitle()
+		var captions internal.CaptionResponse
+		captions, job.err = e.llmClient.GenerateTitleFromContent(e.ctx, job.doc.ID, job.doc.Content)
+		job.captions = &captions
+		activity.decTitle()
+		out <- job
+	}
+}
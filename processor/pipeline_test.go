@@ -0,0 +1,88 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/dhcgn/paperless-ngx-privatemode-ai/config"
+	"github.com/dhcgn/paperless-ngx-privatemode-ai/internal"
+)
+
+var errJobAlreadyFailed = errors.New("already failed upstream")
+
+func TestWorkerCount(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured int
+		fallback   int
+		want       int
+	}{
+		{"unset falls back", 0, 4, 4},
+		{"negative falls back", -1, 4, 4},
+		{"configured wins", 8, 4, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := workerCount(tt.configured, tt.fallback); got != tt.want {
+				t.Errorf("workerCount(%d, %d) = %d, want %d", tt.configured, tt.fallback, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOcrDownloadWorker_DownloadsAndSkipsFailedJobs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("%PDF-fake-bytes"))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{}
+	cfg.Paperless.API.BaseURL = server.URL
+	cfg.Paperless.API.Token = "test-token"
+
+	executor := NewActionExecutor(context.Background(), internal.NewPaperlessClient(cfg), internal.NewLLMClient(cfg), cfg, true)
+
+	in := make(chan *ocrJob, 2)
+	out := make(chan *ocrJob, 2)
+	var wg sync.WaitGroup
+	activity := &stageActivity{}
+
+	wg.Add(1)
+	go executor.ocrDownloadWorker(in, out, &wg, activity)
+
+	downloadable := &ocrJob{doc: internal.Document{ID: 1}}
+	alreadyFailed := &ocrJob{doc: internal.Document{ID: 2}, err: errJobAlreadyFailed}
+	in <- downloadable
+	in <- alreadyFailed
+	close(in)
+	wg.Wait()
+	close(out)
+
+	var results []*ocrJob
+	for job := range out {
+		results = append(results, job)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d jobs out, want 2", len(results))
+	}
+
+	for _, job := range results {
+		switch job.doc.ID {
+		case 1:
+			if job.err != nil || string(job.pdfBytes) != "%PDF-fake-bytes" {
+				t.Errorf("job 1 = pdfBytes=%q err=%v, want downloaded bytes and no error", job.pdfBytes, job.err)
+			}
+		case 2:
+			if job.err != errJobAlreadyFailed {
+				t.Errorf("job 2 err = %v, want the pre-existing error to pass through untouched", job.err)
+			}
+			if job.pdfBytes != nil {
+				t.Error("job 2 should not have been downloaded since it already had an error")
+			}
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package processor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/dhcgn/paperless-ngx-privatemode-ai/internal"
+	"github.com/pterm/pterm"
+)
+
+// WebhookServer runs a long-lived HTTP listener that accepts Paperless-NGX
+// post-consume webhook calls and dispatches non-interactive OCR and title
+// generation for each document named in them. Incoming document IDs are
+// pushed onto an internal channel so the HTTP handler returns immediately;
+// a fixed pool of background workers drains the channel.
+type WebhookServer struct {
+	executor *ActionExecutor
+	queue    chan int
+}
+
+// NewWebhookServer creates a server that processes documents using executor,
+// which must be autonomous (see NewActionExecutor) so title and content
+// selection don't block on interactive prompts. workers controls how many
+// documents are processed concurrently; it is clamped to at least 1.
+func NewWebhookServer(executor *ActionExecutor, workers int) *WebhookServer {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	s := &WebhookServer{
+		executor: executor,
+		queue:    make(chan int, 100),
+	}
+	for i := 0; i < workers; i++ {
+		go s.worker()
+	}
+	return s
+}
+
+type webhookPayload struct {
+	DocumentID int `json:"document_id"`
+}
+
+func (s *WebhookServer) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var payload webhookPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, fmt.Sprintf("invalid payload: %v", err), http.StatusBadRequest)
+		return
+	}
+	if payload.DocumentID <= 0 {
+		http.Error(w, "document_id is required", http.StatusBadRequest)
+		return
+	}
+
+	select {
+	case s.queue <- payload.DocumentID:
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		http.Error(w, "queue full, try again later", http.StatusServiceUnavailable)
+	}
+}
+
+func (s *WebhookServer) worker() {
+	for documentID := range s.queue {
+		if err := s.processDocument(documentID); err != nil {
+			pterm.Error.Printf("Failed to process document %d from webhook: %v\n", documentID, err)
+		}
+	}
+}
+
+// processDocument runs the same OCR-then-title pipeline as the interactive
+// actions, but over a single document and with no user callback so it never
+// blocks waiting for input.
+func (s *WebhookServer) processDocument(documentID int) error {
+	doc, err := s.executor.paperlessClient.GetDocument(s.executor.ctx, documentID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch document %d: %w", documentID, err)
+	}
+
+	if err := s.executor.processOCRGeneration([]internal.Document{doc}, nil); err != nil {
+		return fmt.Errorf("failed to OCR document %d: %w", documentID, err)
+	}
+
+	doc, err = s.executor.paperlessClient.GetDocument(s.executor.ctx, documentID)
+	if err != nil {
+		return fmt.Errorf("failed to refetch document %d: %w", documentID, err)
+	}
+
+	if err := s.executor.processDocumentsForTitleGeneration([]internal.Document{doc}, nil); err != nil {
+		return fmt.Errorf("failed to generate title for document %d: %w", documentID, err)
+	}
+
+	return nil
+}
+
+// Serve registers the /webhook handler and blocks serving HTTP on addr.
+func (s *WebhookServer) Serve(addr string) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/webhook", s.handleWebhook)
+
+	pterm.Info.Printf("Webhook server listening on %s (POST /webhook with {\"document_id\": N})\n", addr)
+	return http.ListenAndServe(addr, mux)
+}
@@ -1,9 +1,11 @@
 package processor
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"sort"
 	"strings"
+	"sync"
 
 	"github.com/dhcgn/paperless-ngx-privatemode-ai/config"
 	"github.com/dhcgn/paperless-ngx-privatemode-ai/internal"
@@ -17,6 +19,7 @@ const (
 	skipDocumentOption       UserOption = "Skip this document"
 	makeOcrAndTryAgainOption UserOption = "Make OCR and try again"
 	customTitleOption        UserOption = "Enter custom title"
+	previewDiffOption        UserOption = "Preview diff of all pending changes"
 )
 
 type Action interface {
@@ -24,508 +27,422 @@ type Action interface {
 	Execute(executor *ActionExecutor) error
 }
 
+// ActionExecutor carries ctx through every Action it runs: pipeline stages
+// (processor/pipeline.go) and the clients they call (internal.PaperlessClient,
+// internal.LLMClient) check it between documents, so a long batch run
+// cancelled by main.go's signal.NotifyContext handler stops starting new
+// work instead of running to completion.
 type ActionExecutor struct {
 	paperlessClient *internal.PaperlessClient
 	llmClient       *internal.LLMClient
 	config          *config.Config
+	autonomous      bool
+	ctx             context.Context
+	// resumeIDs holds document IDs already processed by a prior, cancelled
+	// run (see main.go's --resume flag); processOCRGeneration and
+	// processDocumentsForTitleGeneration filter them out before dispatch.
+	resumeIDs map[int]bool
+	// stats is the ProgressStats of the most recently started OCR or title
+	// generation pipeline, kept around so main.go can read Checkpoint()
+	// after Execute returns early from a cancelled ctx.
+	stats *ProgressStats
+	// nonInteractive suppresses prompts that would otherwise block on
+	// stdin (confirmations, title selection), auto-accepting the default
+	// choice instead. Set by main.go's --non-interactive flag, or implied
+	// by --output=json since a JSON event stream has no one to answer a
+	// prompt.
+	nonInteractive bool
+	// jsonOutput streams newline-delimited JSON events (see emitEvent) to
+	// stdout instead of relying on pterm's human-oriented widgets, set by
+	// main.go's --output=json. main.go also redirects pterm's own output
+	// to stderr in this mode, so stdout carries only the event stream.
+	jsonOutput bool
+	// dryRun makes SetTitleOfPaperlessDocument and SetContentOfPaperlessDocument
+	// short-circuit before calling the Paperless API, set by main.go's
+	// --dry-run flag. Every recorded change still goes through changes
+	// below, so a dry run ends with a full diff preview of what it would
+	// have written.
+	dryRun bool
+	// noPager disables maybePageFilteredDocuments regardless of terminal
+	// size, set by main.go's --no-pager flag.
+	noPager bool
+	// changesMu guards changes, recorded concurrently by the OCR and title
+	// pipelines (see recordChange).
+	changesMu sync.Mutex
+	// changes accumulates every title/content change decided so far in this
+	// process, dry-run or not: renderChangesTable prints them as a diff
+	// table at the end of a dry run, and askUserForTitleSelection's "preview
+	// diff" option prints them mid-batch.
+	changes []internal.DiffEntry
+	// concurrency, if positive, overrides the Download/Render/LLM worker
+	// counts in config.Processing.Workers uniformly for a caller that wants
+	// one fan-out knob instead of tuning each stage separately (see
+	// SetConcurrency and documentWorkerCount).
+	concurrency int
+	// checkpointPath, if set via SetCheckpointTarget, is where persistCheckpoint
+	// writes a Checkpoint after every document this run processes.
+	checkpointPath string
+	// checkpointAction and filterHash tag the Checkpoint written to
+	// checkpointPath, so a later --resume attempt can detect it's resuming
+	// into a different action or filter configuration (see
+	// internal.HashFilterParams).
+	checkpointAction string
+	filterHash       string
 }
 
-func NewActionExecutor(paperlessClient *internal.PaperlessClient, llmClient *internal.LLMClient, config *config.Config) *ActionExecutor {
-	return &ActionExecutor{
-		paperlessClient: paperlessClient,
-		llmClient:       llmClient,
-		config:          config,
-	}
+// SetNonInteractive suppresses prompts that would otherwise block on stdin,
+// for main.go's --non-interactive flag.
+func (e *ActionExecutor) SetNonInteractive(v bool) {
+	e.nonInteractive = v
 }
 
-func (e *ActionExecutor) Execute(action Action) error {
-	return action.Execute(e)
+// SetJSONOutput enables newline-delimited JSON event emission (see
+// emitEvent) and implies SetNonInteractive, for main.go's --output=json
+// flag.
+func (e *ActionExecutor) SetJSONOutput(v bool) {
+	e.jsonOutput = v
+	if v {
+		e.nonInteractive = true
+	}
 }
 
-// SetTitleAction - Set document titles which title contains pattern
-type SetTitleAction struct{}
-
-func (a *SetTitleAction) Description() string {
-	return "Set document titles which title contains pattern"
+// SetDryRun makes SetTitleOfPaperlessDocument and
+// SetContentOfPaperlessDocument short-circuit before calling the Paperless
+// API, for main.go's --dry-run flag.
+func (e *ActionExecutor) SetDryRun(v bool) {
+	e.dryRun = v
 }
 
-func (a *SetTitleAction) Execute(executor *ActionExecutor) error {
-	// Get all documents
-	pterm.Info.Println("Fetching documents from Paperless NGX...")
-	documents, err := executor.paperlessClient.GetDocuments()
-	if err != nil {
-		return fmt.Errorf("failed to get documents: %w", err)
-	}
-	pterm.Success.Printf("Fetched %d documents\n", len(documents))
-
-	// Filter documents by title pattern
-	pterm.Info.Println("Filtering documents by title pattern...")
-	filteredDocs, err := executor.paperlessClient.FilterDocuments(documents, internal.FilterTypeTitle)
-	if err != nil {
-		return fmt.Errorf("failed to filter documents: %w", err)
-	}
-	pterm.Success.Printf("Found %d documents matching title patterns\n", len(filteredDocs))
+// IsDryRun reports whether dryRun is set, so SetTitleOfPaperlessDocument
+// and SetContentOfPaperlessDocument (see processor/headless_actions.go) can
+// skip the actual Paperless API call.
+func (e *ActionExecutor) IsDryRun() bool {
+	return e.dryRun
+}
 
-	if len(filteredDocs) == 0 {
-		pterm.Warning.Println("No documents found matching the title patterns")
-		return nil
-	}
+// SetNoPager disables maybePageFilteredDocuments regardless of terminal
+// size, for main.go's --no-pager flag.
+func (e *ActionExecutor) SetNoPager(v bool) {
+	e.noPager = v
+}
 
-	// Display bar chart with document counts
-	bars := []pterm.Bar{
-		{Label: "All", Value: len(documents), Style: pterm.NewStyle(pterm.FgGray)},
-		{Label: "Found", Value: len(filteredDocs), Style: pterm.NewStyle(pterm.FgGreen)},
-	}
-	pterm.DefaultBarChart.WithHorizontal().WithBars(bars).WithShowValue().Render()
+// SetConcurrency overrides the Download/Render/LLM worker counts in
+// config.Processing.Workers uniformly, for a caller that wants one
+// programmatic fan-out knob instead of tuning each stage separately (e.g.
+// main.go's --concurrency flag). A value <= 0 clears the override, falling
+// back to config.Processing.Workers.MaxParallel and then the per-stage
+// defaults. It has no effect on any stage whose own Workers field is
+// already set.
+func (e *ActionExecutor) SetConcurrency(n int) {
+	e.concurrency = n
+}
 
-	// Ask for confirmation
-	confirmed, err := pterm.DefaultInteractiveConfirm.
-		WithDefaultValue(false).
-		WithDefaultText("Do you want to generate new titles for these documents using LLM?").
-		Show()
-	if err != nil {
-		return fmt.Errorf("failed to get confirmation: %w", err)
+// documentWorkerCount resolves how many goroutines a document-pipeline
+// stage should run: configured (the stage's own Workers field) wins if set,
+// then e.concurrency (SetConcurrency), then config.Processing.Workers.MaxParallel,
+// then fallback.
+func (e *ActionExecutor) documentWorkerCount(configured, fallback int) int {
+	if configured > 0 {
+		return configured
 	}
-
-	if !confirmed {
-		pterm.Info.Println("Operation cancelled by user")
-		return nil
+	if e.concurrency > 0 {
+		return e.concurrency
 	}
-
-	// Process documents
-	return executor.processDocumentsForTitleGeneration(filteredDocs, func(doc internal.Document, captionResp internal.CaptionResponse) (string, bool) {
-		// Show document summary first
-		if captionResp.Summarize != "" {
-			pterm.Info.Printf("Document Summary: %s\n\n", captionResp.Summarize)
-		}
-
-	AskForTitleSelection:
-		selectedOption, userSelectedTitle, err := AskForTitleSelection(captionResp, doc.Title, doc.ID, executor.config.CreateUrl(doc.ID))
-		if err != nil {
-			return "", false
-		}
-		if userSelectedTitle != "" {
-			return userSelectedTitle, true
-		}
-
-		// Check if user chose to skip
-		if selectedOption == skipDocumentOption {
-			return "", false
-		}
-
-		// Check if user chose to make OCR and try again
-		if selectedOption == makeOcrAndTryAgainOption {
-			pterm.Info.Println("Making OCR and trying again...")
-			// Call the OCR generation process
-			c := HeadlessActionClients{
-				Config:          executor.config,
-				PaperlessClient: executor.paperlessClient,
-				LLMClient:       executor.llmClient,
-			}
-			_, captions, err := c.OcrPaperlessDocument(doc.ID, func(status string) {
-				pterm.Info.Println(status)
-			})
-			if err != nil {
-				pterm.Error.Printf("Failed to make OCR and generate title: %v\n", err)
-				return "", false
-			}
-			if len(captions.Captions) == 0 {
-				pterm.Warning.Println("No titles generated after OCR, skipping document")
-				return "", false
-			}
-			captionResp = *captions
-
-			goto AskForTitleSelection // Re-ask for title selection with new captions
-
-		}
-
-		return "", false
-	})
+	return workerCount(e.config.Processing.Workers.MaxParallel, fallback)
 }
 
-func AskForTitleSelection(captionResp internal.CaptionResponse, currentTitle string, id int, url string) (UserOption, string, error) {
-	// Sort captions by score (highest score first)
-	sort.Slice(captionResp.Captions, func(i, j int) bool {
-		return captionResp.Captions[i].Score > captionResp.Captions[j].Score
+// recordChange appends a decided title/content change to changes, for
+// renderChangesTable and main.go's .diff.jsonl report.
+func (e *ActionExecutor) recordChange(id int, field, oldValue, newValue, reason string, score float64) {
+	e.changesMu.Lock()
+	defer e.changesMu.Unlock()
+	e.changes = append(e.changes, internal.DiffEntry{
+		ID:     id,
+		URL:    e.config.CreateUrl(id),
+		Field:  field,
+		Old:    oldValue,
+		New:    newValue,
+		Score:  score,
+		Reason: reason,
 	})
+}
 
-	mapTitleToOptions := make(map[string]string)
-
-	// Prepare options for user selection
-	options := make([]string, 0, len(captionResp.Captions)+2)
+// Changes returns a copy of every title/content change decided so far in
+// this process, for main.go's .diff.jsonl report after a --dry-run run.
+func (e *ActionExecutor) Changes() []internal.DiffEntry {
+	e.changesMu.Lock()
+	defer e.changesMu.Unlock()
+	out := make([]internal.DiffEntry, len(e.changes))
+	copy(out, e.changes)
+	return out
+}
 
-	// Add each caption with its score
-	for i, caption := range captionResp.Captions {
-		optDisplayTitleWithScore := fmt.Sprintf("%d. %s (Score: %.2f)", i+1, caption.Caption, caption.Score)
-		options = append(options, optDisplayTitleWithScore)
-		mapTitleToOptions[optDisplayTitleWithScore] = caption.Caption
+// defaultDiffContextChars is the old/new value preview length used when
+// config.Processing.DryRun.ContextChars is unset.
+const defaultDiffContextChars = 200
+
+// renderChangesTable prints changes as a colourised unified diff - one
+// "--- old" / "+++ new" block per change, headed by the document's ID, URL,
+// and the reason it changed - truncated to contextChars of old/new value so
+// a multi-page OCR'd document's diff doesn't scroll the rest off-screen.
+// Used both for the end-of-run dry-run preview and
+// askUserForTitleSelection's "preview diff" option.
+func renderChangesTable(changes []internal.DiffEntry, contextChars int) {
+	if len(changes) == 0 {
+		pterm.Info.Println("No pending changes to preview")
+		return
 	}
-
-	// Add option for custom title
-	options = append(options, string(customTitleOption))
-
-	// Add option to skip
-	options = append(options, string(skipDocumentOption))
-
-	// Add option to make OCR and try again
-	options = append(options, string(makeOcrAndTryAgainOption))
-
-	// Show interactive select
-	selectedOption, err := pterm.DefaultInteractiveSelect.
-		WithOptions(options).
-		WithDefaultOption(string(skipDocumentOption)).
-		Show(fmt.Sprintf("Choose a new title for document '%s' (id: %d):\nUrl: %s\n", currentTitle, id, url))
-
-	if err != nil {
-		return Undefined, "", fmt.Errorf("failed to get user selection: %w", err)
+	if contextChars <= 0 {
+		contextChars = defaultDiffContextChars
 	}
 
-	// Check if the selected option is valid
-	if selectedOption == "" {
-		return Undefined, "", fmt.Errorf("no valid option selected")
+	gray := pterm.NewStyle(pterm.FgGray)
+	red := pterm.NewStyle(pterm.FgRed)
+	green := pterm.NewStyle(pterm.FgGreen)
+	for _, c := range changes {
+		gray.Println(fmt.Sprintf("# document %d (%s) - %s", c.ID, c.Field, c.URL))
+		if c.Reason != "" {
+			gray.Println(fmt.Sprintf("# %s (score %.2f)", c.Reason, c.Score))
+		}
+		red.Println(fmt.Sprintf("--- %s", truncateContext(c.Old, contextChars)))
+		green.Println(fmt.Sprintf("+++ %s", truncateContext(c.New, contextChars)))
 	}
+}
 
-	// Declare userOption before the switch
-	var userOption UserOption
-
-	// Check if the selected option is one of the custom options using a switch for clarity
-	switch selectedOption {
-	case string(skipDocumentOption):
-		userOption = skipDocumentOption
-	case string(makeOcrAndTryAgainOption):
-		userOption = makeOcrAndTryAgainOption
-	case string(customTitleOption):
-		userOption = customTitleOption
-	default:
-		userOption = Undefined
+// truncateContext collapses newlines to keep s on one line, then truncates
+// it to at most n characters, for renderChangesTable's diff preview.
+func truncateContext(s string, n int) string {
+	s = strings.ReplaceAll(s, "\n", "↵")
+	if len(s) > n {
+		return s[:n] + "..."
 	}
+	return s
+}
 
-	customUserTitle := ""
-	if userOption == customTitleOption {
-		pterm.Println()
-		pterm.Info.Println("Please enter your custom title:")
-
-		// Create an interactive text input with single line input mode and show it
-		result, err := pterm.DefaultInteractiveTextInput.Show()
-		if err != nil {
-			pterm.Error.Printf("Failed to get custom title input: %v\n", err)
-			return userOption, "", fmt.Errorf("failed to get custom title input: %w", err)
-		}
-
-		// Print a blank line for better readability
-		pterm.Println()
-
-		// Check if user entered something
-		if strings.TrimSpace(result) == "" {
-			pterm.Warning.Println("No title entered, skipping document")
-			return userOption, "", nil
-		}
-
-		// Print the user's answer with an info prefix
-		pterm.Info.Printfln("You entered: %s", result)
-
-		return userOption, strings.TrimSpace(result), nil
-	} else if userOption != Undefined {
-		return userOption, "", nil
+// emitEvent writes event as a single line of JSON to stdout when
+// jsonOutput is set; it's a no-op otherwise, since pterm already prints a
+// human-readable equivalent at each call site.
+func (e *ActionExecutor) emitEvent(event map[string]interface{}) {
+	if !e.jsonOutput {
+		return
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
 	}
+	fmt.Println(string(data))
+}
 
-	// If the selected option is one of the captions, return the corresponding title
-	customUserTitle, exists := mapTitleToOptions[selectedOption]
-	if !exists {
-		return Undefined, "", fmt.Errorf("selected option '%s' does not correspond to any title", selectedOption)
+func NewActionExecutor(ctx context.Context, paperlessClient *internal.PaperlessClient, llmClient *internal.LLMClient, config *config.Config, autonomous bool) *ActionExecutor {
+	if ctx == nil {
+		ctx = context.Background()
 	}
-	// If the selected option is a valid caption, return it
-	if customUserTitle == "" {
-		return Undefined, "", fmt.Errorf("no title found for selected option '%s'", selectedOption)
+	return &ActionExecutor{
+		paperlessClient: paperlessClient,
+		llmClient:       llmClient,
+		config:          config,
+		autonomous:      autonomous,
+		ctx:             ctx,
 	}
-
-	return userOption, customUserTitle, nil
 }
 
-// SetContentAction - Set document content which content contains pattern
-type SetContentAction struct{}
-
-func (a *SetContentAction) Description() string {
-	return "Set document content which content contains pattern"
+func (e *ActionExecutor) Execute(action Action) error {
+	return action.Execute(e)
 }
 
-func (a *SetContentAction) Execute(executor *ActionExecutor) error {
-	// Get all documents
-	pterm.Info.Println("Fetching documents from Paperless NGX...")
-	documents, err := executor.paperlessClient.GetDocuments()
-	if err != nil {
-		return fmt.Errorf("failed to get documents: %w", err)
+// SetResumeIDs marks document IDs already processed by a prior, cancelled
+// run so processOCRGeneration and processDocumentsForTitleGeneration skip
+// them instead of redoing the work (see main.go's --resume flag).
+func (e *ActionExecutor) SetResumeIDs(ids []int) {
+	e.resumeIDs = make(map[int]bool, len(ids))
+	for _, id := range ids {
+		e.resumeIDs[id] = true
 	}
-	pterm.Success.Printf("Fetched %d documents\n", len(documents))
+}
 
-	// Filter documents by content pattern
-	pterm.Info.Println("Filtering documents by content pattern...")
-	filteredDocs, err := executor.paperlessClient.FilterDocuments(documents, internal.FilterTypeContent)
-	if err != nil {
-		return fmt.Errorf("failed to filter documents: %w", err)
+// skipResumed drops any document whose ID was already processed in a prior
+// run, logging how many were skipped. It is a no-op when no resume IDs are
+// set.
+func (e *ActionExecutor) skipResumed(documents []internal.Document) []internal.Document {
+	if len(e.resumeIDs) == 0 {
+		return documents
 	}
-	pterm.Success.Printf("Found %d documents matching content patterns\n", len(filteredDocs))
 
-	if len(filteredDocs) == 0 {
-		pterm.Warning.Println("No documents found matching the content patterns")
-		return nil
+	remaining := make([]internal.Document, 0, len(documents))
+	for _, doc := range documents {
+		if !e.resumeIDs[doc.ID] {
+			remaining = append(remaining, doc)
+		}
 	}
-
-	// Display bar chart with document counts
-	bars := []pterm.Bar{
-		{Label: "All", Value: len(documents), Style: pterm.NewStyle(pterm.FgGray)},
-		{Label: "Found", Value: len(filteredDocs), Style: pterm.NewStyle(pterm.FgGreen)},
+	if skipped := len(documents) - len(remaining); skipped > 0 {
+		pterm.Info.Printf("Skipping %d document(s) already processed in the run being resumed\n", skipped)
 	}
-	pterm.DefaultBarChart.WithHorizontal().WithBars(bars).WithShowValue().Render()
+	return remaining
+}
 
-	// Ask for confirmation
-	confirmed, err := pterm.DefaultInteractiveConfirm.
-		WithDefaultValue(false).
-		WithDefaultText("Do you want to extract content for these documents using LLM?").
-		Show()
-	if err != nil {
-		return fmt.Errorf("failed to get confirmation: %w", err)
+// Counts reports the outcome totals of the most recently started OCR or
+// title generation pipeline, for main.go to derive a process exit code in
+// --non-interactive / --output=json runs.
+func (e *ActionExecutor) Counts() (total, success, errors, skipped int) {
+	if e.stats == nil {
+		return 0, 0, 0, 0
 	}
+	total, _, success, errors, skipped = e.stats.snapshot()
+	return
+}
 
-	if !confirmed {
-		pterm.Info.Println("Operation cancelled by user")
-		return nil
+// Checkpoint reports the document IDs processed, their per-ID status, and
+// outcome counts of the most recently started pipeline, for main.go to
+// persist when a batch run is interrupted (see its signal.NotifyContext
+// handler and --resume flag) or after every document (see persistCheckpoint).
+func (e *ActionExecutor) Checkpoint() internal.Checkpoint {
+	if e.stats == nil {
+		return internal.Checkpoint{}
 	}
+	_, _, success, errors, skipped := e.stats.snapshot()
+	statuses := e.stats.statusesSnapshot()
+	ids := make([]int, 0, len(statuses))
+	for id := range statuses {
+		ids = append(ids, id)
+	}
+	return internal.Checkpoint{
+		Action:       e.checkpointAction,
+		FilterHash:   e.filterHash,
+		Statuses:     statuses,
+		ProcessedIDs: ids,
+		Success:      success,
+		Errors:       errors,
+		Skipped:      skipped,
+	}
+}
 
-	// Process documents
-	return executor.processOCRGeneration(filteredDocs, func(doc internal.Document, newContent string, newTitle string) bool {
-		previewContent := newContent
-		if len(newContent) > 50 {
-			previewContent = newContent[:50] + "..."
-		}
-
-		// Ask for user confirmation
-		confirmed, err := pterm.DefaultInteractiveConfirm.
-			WithDefaultValue(false).
-			WithDefaultText(fmt.Sprintf(
-				"Do you want to change the content of document '%s' to '%v' chars (title could be '%s')?\n"+
-					"Url: %s\n"+
-					"First 50 chars: %s\n"+
-					"Change content?",
-				doc.Title, len(newContent), newTitle, executor.config.CreateUrl(doc.ID), previewContent,
-			)).
-			Show()
-		if err != nil {
-			return false
-		}
-		return confirmed
-	})
+// SetCheckpointTarget arms persistCheckpoint to write a checkpoint to path
+// after every document this run processes, tagged with action and
+// filterHash (see internal.HashFilterParams) so a later --resume attempt
+// can tell whether it's resuming into the same filter configuration. An
+// empty path disables per-document persistence; main.go still calls
+// writeCheckpoint once more on interrupt regardless.
+func (e *ActionExecutor) SetCheckpointTarget(path, action, filterHash string) {
+	e.checkpointPath = path
+	e.checkpointAction = action
+	e.filterHash = filterHash
 }
 
-func (e *ActionExecutor) processOCRGeneration(documents []internal.Document, userCallback func(internal.Document, string, string) bool) error {
-	stats := &ProgressStats{
-		processed: 0,
-		success:   0,
-		errors:    0,
-		skipped:   0,
-		total:     len(documents),
+// persistCheckpoint writes the current Checkpoint to e.checkpointPath, if
+// SetCheckpointTarget armed one, so a crash (not just a Ctrl-C caught by
+// main.go's signal handler) loses at most the document in flight. Errors
+// are logged rather than returned since a failed checkpoint write shouldn't
+// abort an otherwise-successful document.
+func (e *ActionExecutor) persistCheckpoint() {
+	if e.checkpointPath == "" {
+		return
 	}
+	if err := internal.SaveCheckpoint(e.checkpointPath, e.Checkpoint()); err != nil {
+		pterm.Warning.Printf("Failed to persist checkpoint: %v\n", err)
+	}
+}
 
-	pterm.Info.Println("Starting OCR generation process...")
-
-	for _, doc := range documents {
-		// Download document pdf
-		pdfBytes, err := e.paperlessClient.DownloadDocument(doc.ID)
-		if err != nil {
-			pterm.Warning.Printf("Failed to download PDF for document %d: %v\n", doc.ID, err)
-			stats.errors++
-			stats.processed++
-			stats.renderProgressChart()
-			continue
-		}
-
-		// Convert first page to JPEG
-		jpegData, err := internal.RenderPageToJpg(e.config, pdfBytes, 0)
-		if err != nil {
-			pterm.Warning.Printf("Failed to render page to JPG for document %d: %v\n", doc.ID, err)
-			stats.errors++
-			stats.processed++
-			stats.renderProgressChart()
-			continue
-		}
-
-		// Extract content using LLM
-		newContent, err := e.llmClient.MakeOcr(jpegData)
-		if err != nil {
-			pterm.Warning.Printf("Failed to extract content for document %d: %v\n", doc.ID, err)
-			stats.errors++
-			stats.processed++
-			stats.renderProgressChart()
-			continue
-		}
+// ProgressStats tracks how many documents have been processed across a
+// pipeline run. Pipeline stages execute concurrently (see
+// processor/pipeline.go), so every field access goes through mu.
+type ProgressStats struct {
+	mu        sync.Mutex
+	total     int
+	processed int
+	success   int
+	errors    int
+	skipped   int
+	// statuses maps a document ID to "success", "error", or "skipped", for
+	// ActionExecutor.Checkpoint/persistCheckpoint and a later --resume run
+	// that wants to retry just the error rows.
+	statuses map[int]string
+}
 
-		// Generate new titles using LLM
-		captions, err := e.llmClient.GenerateTitleFromContent(newContent)
-		if err != nil {
-			pterm.Warning.Printf("Failed to generate title for document %d: %v\n", doc.ID, err)
-			stats.errors++
-			stats.processed++
-			stats.renderProgressChart()
-			continue
-		}
+func (stats *ProgressStats) incSuccess(documentID int) {
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+	stats.processed++
+	stats.success++
+	stats.setStatus(documentID, "success")
+}
 
-		if len(captions.Captions) == 0 {
-			pterm.Warning.Printf("No titles generated for document %d\n", doc.ID)
-			stats.errors++
-			stats.processed++
-			stats.renderProgressChart()
-			continue
-		}
+func (stats *ProgressStats) incError(documentID int) {
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+	stats.processed++
+	stats.errors++
+	stats.setStatus(documentID, "error")
+}
 
-		// Sort captions by score (highest score first)
-		sort.Slice(captions.Captions, func(i, j int) bool {
-			return captions.Captions[i].Score > captions.Captions[j].Score
-		})
-
-		newTitle := captions.Captions[0].Caption
-
-		if userCallback != nil {
-			pterm.Info.Println("Start User Interaction")
-			if !userCallback(doc, newContent, newTitle) {
-				pterm.Warning.Println("User cancelled this operation")
-				stats.skipped++
-				stats.processed++
-				stats.renderProgressChart()
-				continue
-			}
-			pterm.Info.Println("End of User Interaction")
-		}
+func (stats *ProgressStats) incSkipped(documentID int) {
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+	stats.processed++
+	stats.skipped++
+	stats.setStatus(documentID, "skipped")
+}
 
-		// Update document content
-		updates := map[string]interface{}{
-			"content": newContent,
-		}
+// setStatus records documentID's outcome. Callers must hold stats.mu.
+func (stats *ProgressStats) setStatus(documentID int, status string) {
+	if stats.statuses == nil {
+		stats.statuses = make(map[int]string)
+	}
+	stats.statuses[documentID] = status
+}
 
-		if err := e.paperlessClient.UpdateDocument(doc.ID, updates); err != nil {
-			pterm.Warning.Printf("Failed to update document %d: %v\n", doc.ID, err)
-			stats.errors++
-		} else {
-			stats.success++
-		}
+func (stats *ProgressStats) snapshot() (total, processed, success, errors, skipped int) {
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+	return stats.total, stats.processed, stats.success, stats.errors, stats.skipped
+}
 
-		stats.processed++
-		stats.renderProgressChart()
+// statusesSnapshot returns a copy of the per-document outcome recorded so
+// far, for ActionExecutor.Checkpoint.
+func (stats *ProgressStats) statusesSnapshot() map[int]string {
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+	out := make(map[int]string, len(stats.statuses))
+	for id, status := range stats.statuses {
+		out[id] = status
 	}
-
-	stats.renderFinalSummary(len(documents))
-	return nil
+	return out
 }
 
-type ProgressStats struct {
-	total     int
-	processed int
-	success   int
-	errors    int
-	skipped   int
+func (stats *ProgressStats) done() bool {
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+	return stats.processed >= stats.total
 }
 
 func (stats *ProgressStats) renderProgressChart() {
+	total, processed, success, errors, skipped := stats.snapshot()
 	bars := []pterm.Bar{
-		{Label: "Total", Value: stats.total, Style: pterm.NewStyle(pterm.FgGray)},
-		{Label: "Processed", Value: stats.processed, Style: pterm.NewStyle(pterm.FgBlue)},
-		{Label: "Success", Value: stats.success, Style: pterm.NewStyle(pterm.FgGreen)},
-		{Label: "Errors", Value: stats.errors, Style: pterm.NewStyle(pterm.FgRed)},
-		{Label: "Skipped", Value: stats.skipped, Style: pterm.NewStyle(pterm.FgYellow)},
+		{Label: "Total", Value: total, Style: pterm.NewStyle(pterm.FgGray)},
+		{Label: "Processed", Value: processed, Style: pterm.NewStyle(pterm.FgBlue)},
+		{Label: "Success", Value: success, Style: pterm.NewStyle(pterm.FgGreen)},
+		{Label: "Errors", Value: errors, Style: pterm.NewStyle(pterm.FgRed)},
+		{Label: "Skipped", Value: skipped, Style: pterm.NewStyle(pterm.FgYellow)},
 	}
 	pterm.DefaultBarChart.WithHorizontal().WithBars(bars).WithShowValue().Render()
 }
 
 func (stats *ProgressStats) renderFinalSummary(totalDocuments int) {
-	pterm.Success.Printf("Successfully updated %d documents\n", stats.success)
-	if stats.errors > 0 {
-		pterm.Warning.Printf("Failed to update %d documents\n", stats.errors)
+	_, _, success, errors, skipped := stats.snapshot()
+
+	pterm.Success.Printf("Successfully updated %d documents\n", success)
+	if errors > 0 {
+		pterm.Warning.Printf("Failed to update %d documents\n", errors)
 	}
-	if stats.skipped > 0 {
-		pterm.Info.Printf("Skipped %d documents\n", stats.skipped)
+	if skipped > 0 {
+		pterm.Info.Printf("Skipped %d documents\n", skipped)
 	}
 
 	bars := []pterm.Bar{
 		{Label: "Total", Value: totalDocuments, Style: pterm.NewStyle(pterm.FgGray)},
-		{Label: "Success", Value: stats.success, Style: pterm.NewStyle(pterm.FgGreen)},
-		{Label: "Errors", Value: stats.errors, Style: pterm.NewStyle(pterm.FgRed)},
-		{Label: "Skipped", Value: stats.skipped, Style: pterm.NewStyle(pterm.FgYellow)},
+		{Label: "Success", Value: success, Style: pterm.NewStyle(pterm.FgGreen)},
+		{Label: "Errors", Value: errors, Style: pterm.NewStyle(pterm.FgRed)},
+		{Label: "Skipped", Value: skipped, Style: pterm.NewStyle(pterm.FgYellow)},
 	}
 	pterm.Info.Println("Final Summary:")
 	pterm.DefaultBarChart.WithHorizontal().WithBars(bars).WithShowValue().Render()
 }
-
-func (e *ActionExecutor) processDocumentsForTitleGeneration(documents []internal.Document, userCallback func(internal.Document, internal.CaptionResponse) (string, bool)) error {
-	stats := &ProgressStats{
-		processed: 0,
-		success:   0,
-		errors:    0,
-		skipped:   0,
-		total:     len(documents),
-	}
-
-	pterm.Info.Println("Starting title generation process...")
-
-	for _, doc := range documents {
-		// Generate new titles using LLM
-		pterm.Info.Printf("Generating title for document '%s' (id: %d, link: %s)\n", doc.Title, doc.ID, e.config.CreateUrl(doc.ID))
-		captions, err := e.llmClient.GenerateTitleFromContent(doc.Content)
-		if err != nil {
-			pterm.Warning.Printf("Failed to generate title for document %d: %v\n", doc.ID, err)
-			stats.errors++
-			stats.processed++
-			stats.renderProgressChart()
-			continue
-		}
-
-		if len(captions.Captions) == 0 {
-			pterm.Warning.Printf("No titles generated for document %d\n", doc.ID)
-			stats.errors++
-			stats.processed++
-			stats.renderProgressChart()
-			continue
-		}
-
-		// Sort captions by score (highest score first)
-		sort.Slice(captions.Captions, func(i, j int) bool {
-			return captions.Captions[i].Score > captions.Captions[j].Score
-		})
-
-		var selectedTitle string
-		var userConfirmed bool
-
-		if userCallback != nil {
-			pterm.Info.Println("Start User Interaction")
-			selectedTitle, userConfirmed = userCallback(doc, captions)
-			if !userConfirmed {
-				pterm.Warning.Println("User cancelled this operation")
-				stats.skipped++
-				stats.processed++
-				stats.renderProgressChart()
-				continue
-			}
-			pterm.Info.Println("End of User Interaction")
-		} else {
-			// Use the first generated title if no callback
-			selectedTitle = captions.Captions[0].Caption
-			userConfirmed = true
-		}
-
-		// Update document title
-		updates := map[string]interface{}{
-			"title": selectedTitle,
-		}
-
-		if err := e.paperlessClient.UpdateDocument(doc.ID, updates); err != nil {
-			pterm.Warning.Printf("Failed to update document %d: %v\n", doc.ID, err)
-			stats.errors++
-		} else {
-			stats.success++
-		}
-
-		stats.processed++
-		stats.renderProgressChart()
-	}
-
-	stats.renderFinalSummary(len(documents))
-	return nil
-}
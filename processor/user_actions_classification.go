@@ -0,0 +1,126 @@
+package processor
+
+import (
+	"fmt"
+
+	"github.com/dhcgn/paperless-ngx-privatemode-ai/internal"
+	"github.com/pterm/pterm"
+)
+
+// defaultClassificationThreshold is the confidence floor used when
+// config.Processing.Classification.Threshold is left unset.
+const defaultClassificationThreshold = 0.6
+
+// ClassifyDocumentsAction fetches the current tag/correspondent/document
+// type taxonomy once, then asks the LLM to classify every document against
+// it via LLMClient.ClassifyDocument, writing the result to Paperless
+// through SetTagsOfPaperlessDocument/SetCorrespondentOfPaperlessDocument/
+// SetDocumentTypeOfPaperlessDocument. This is the LLM-driven counterpart to
+// ApplyRulesAction: where that matches documents against hand-written
+// patterns, this leaves the matching to the model, guarded by a confidence
+// threshold below which a document is left untouched instead of risking a
+// wrong classification.
+type ClassifyDocumentsAction struct{}
+
+func (a *ClassifyDocumentsAction) Description() string {
+	return "Classify documents (tags, correspondent, document type) with the LLM"
+}
+
+func (a *ClassifyDocumentsAction) Execute(executor *ActionExecutor) error {
+	pterm.Info.Println("Fetching taxonomy from Paperless NGX...")
+	taxonomy, err := fetchTaxonomy(executor)
+	if err != nil {
+		return fmt.Errorf("failed to fetch taxonomy: %w", err)
+	}
+
+	pterm.Info.Println("Fetching documents from Paperless NGX...")
+	documents, err := executor.paperlessClient.GetAllDocuments(executor.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get documents: %w", err)
+	}
+	pterm.Success.Printf("Fetched %d documents\n", len(documents))
+
+	threshold := executor.config.Processing.Classification.Threshold
+	if threshold <= 0 {
+		threshold = defaultClassificationThreshold
+	}
+
+	stats := &ProgressStats{total: len(documents)}
+	progressBar, _ := pterm.DefaultProgressbar.WithTitle("Classifying documents").WithTotal(len(documents)).Start()
+	for _, doc := range documents {
+		matched, err := classifyDocument(executor, doc, taxonomy, threshold)
+		switch {
+		case err != nil:
+			pterm.Warning.Printf("Failed to classify document %d: %v\n", doc.ID, err)
+			stats.incError(doc.ID)
+		case matched:
+			stats.incSuccess(doc.ID)
+		default:
+			stats.incSkipped(doc.ID)
+		}
+		progressBar.Increment()
+	}
+	progressBar.Stop()
+
+	stats.renderFinalSummary(len(documents))
+	return nil
+}
+
+// fetchTaxonomy fetches every tag, correspondent, and document type
+// currently defined in Paperless, for LLMClient.ClassifyDocument to
+// constrain its reply to.
+func fetchTaxonomy(executor *ActionExecutor) (internal.Taxonomy, error) {
+	tags, err := executor.paperlessClient.GetTags(executor.ctx)
+	if err != nil {
+		return internal.Taxonomy{}, fmt.Errorf("failed to get tags: %w", err)
+	}
+	correspondents, err := executor.paperlessClient.GetCorrespondents(executor.ctx)
+	if err != nil {
+		return internal.Taxonomy{}, fmt.Errorf("failed to get correspondents: %w", err)
+	}
+	documentTypes, err := executor.paperlessClient.GetDocumentTypes(executor.ctx)
+	if err != nil {
+		return internal.Taxonomy{}, fmt.Errorf("failed to get document types: %w", err)
+	}
+	return internal.Taxonomy{Tags: tags, Correspondents: correspondents, DocumentTypes: documentTypes}, nil
+}
+
+// classifyDocument asks the LLM to classify doc against taxonomy and, if
+// its confidence reaches threshold, writes the result to Paperless,
+// reporting whether anything was written.
+func classifyDocument(executor *ActionExecutor, doc internal.Document, taxonomy internal.Taxonomy, threshold float64) (bool, error) {
+	classification, err := executor.llmClient.ClassifyDocument(executor.ctx, doc.Content, taxonomy)
+	if err != nil {
+		return false, fmt.Errorf("failed to classify: %w", err)
+	}
+
+	if classification.Confidence < threshold {
+		pterm.Info.Printf("Document %d (%s): confidence %.2f below threshold %.2f, leaving untouched\n", doc.ID, doc.Title, classification.Confidence, threshold)
+		return false, nil
+	}
+
+	wrote := false
+	if len(classification.TagIDs) > 0 {
+		if err := SetTagsOfPaperlessDocument(executor.ctx, executor, doc.ID, classification.TagIDs); err != nil {
+			return false, fmt.Errorf("failed to set tags: %w", err)
+		}
+		wrote = true
+	}
+	if classification.CorrespondentID > 0 {
+		if err := SetCorrespondentOfPaperlessDocument(executor.ctx, executor, doc.ID, classification.CorrespondentID); err != nil {
+			return false, fmt.Errorf("failed to set correspondent: %w", err)
+		}
+		wrote = true
+	}
+	if classification.DocumentTypeID > 0 {
+		if err := SetDocumentTypeOfPaperlessDocument(executor.ctx, executor, doc.ID, classification.DocumentTypeID); err != nil {
+			return false, fmt.Errorf("failed to set document type: %w", err)
+		}
+		wrote = true
+	}
+
+	if wrote {
+		pterm.Info.Printf("Classified document %d (%s): %+v\n", doc.ID, doc.Title, classification)
+	}
+	return wrote, nil
+}
@@ -9,7 +9,7 @@ import (
 	"github.com/pterm/pterm"
 )
 
-func askUserForTitleSelection(captionResp internal.CaptionResponse, currentTitle string, id int, url string) (UserOption, string, error) {
+func askUserForTitleSelection(executor *ActionExecutor, captionResp internal.CaptionResponse, currentTitle string, id int, url string) (UserOption, string, error) {
 	// Sort captions by score (highest score first)
 	sort.Slice(captionResp.Captions, func(i, j int) bool {
 		return captionResp.Captions[i].Score > captionResp.Captions[j].Score
@@ -18,7 +18,7 @@ func askUserForTitleSelection(captionResp internal.CaptionResponse, currentTitle
 	mapTitleToOptions := make(map[string]string)
 
 	// Prepare options for user selection
-	options := make([]string, 0, len(captionResp.Captions)+2)
+	options := make([]string, 0, len(captionResp.Captions)+3)
 
 	// Add each caption with its score
 	for i, caption := range captionResp.Captions {
@@ -30,20 +30,33 @@ func askUserForTitleSelection(captionResp internal.CaptionResponse, currentTitle
 	// Add option for custom title
 	options = append(options, string(customTitleOption))
 
+	// Add option to preview every change decided so far in this batch
+	options = append(options, string(previewDiffOption))
+
 	// Add option to skip
 	options = append(options, string(skipDocumentOption))
 
 	// Add option to make OCR and try again
 	options = append(options, string(makeOcrAndTryAgainOption))
 
-	// Show interactive select
-	selectedOption, err := pterm.DefaultInteractiveSelect.
-		WithOptions(options).
-		WithDefaultOption(string(skipDocumentOption)).
-		Show(fmt.Sprintf("Choose a new title for document '%s' (id: %d):\nUrl: %s\n", currentTitle, id, url))
+	// Show interactive select, looping back after a diff preview instead of
+	// forcing the user to decide on this document right away.
+	var selectedOption string
+	var err error
+	for {
+		selectedOption, err = pterm.DefaultInteractiveSelect.
+			WithOptions(options).
+			WithDefaultOption(string(skipDocumentOption)).
+			Show(fmt.Sprintf("Choose a new title for document '%s' (id: %d):\nUrl: %s\n", currentTitle, id, url))
 
-	if err != nil {
-		return Undefined, "", fmt.Errorf("failed to get user selection: %w", err)
+		if err != nil {
+			return Undefined, "", fmt.Errorf("failed to get user selection: %w", err)
+		}
+
+		if selectedOption != string(previewDiffOption) {
+			break
+		}
+		renderChangesTable(executor.Changes(), executor.config.Processing.DryRun.ContextChars)
 	}
 
 	// Check if the selected option is valid
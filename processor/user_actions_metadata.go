@@ -0,0 +1,144 @@
+package processor
+
+import (
+	"fmt"
+
+	"github.com/dhcgn/paperless-ngx-privatemode-ai/internal"
+	"github.com/pterm/pterm"
+)
+
+// ExtractMetadataAction renders each document's first page, extracts
+// structured metadata via LLMClient.ExtractFields, and maps the result onto
+// Paperless's correspondent/document type/custom field model via the
+// existing UpdateDocument path. This is the bridge between free-text OCR
+// and Paperless's structured metadata, the same way ApplyRulesAction
+// bridges document content to routing decisions.
+type ExtractMetadataAction struct{}
+
+func (a *ExtractMetadataAction) Description() string {
+	return "Extract structured metadata (correspondent, date, amount, ...) and write it to Paperless"
+}
+
+func (a *ExtractMetadataAction) Execute(executor *ActionExecutor) error {
+	pterm.Info.Println("Fetching documents from Paperless NGX...")
+	documents, err := executor.paperlessClient.GetAllDocuments(executor.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get documents: %w", err)
+	}
+	pterm.Success.Printf("Fetched %d documents\n", len(documents))
+
+	stats := &ProgressStats{total: len(documents)}
+	progressBar, _ := pterm.DefaultProgressbar.WithTitle("Extracting metadata").WithTotal(len(documents)).Start()
+	for _, doc := range documents {
+		if err := executor.extractMetadataForDocument(doc); err != nil {
+			pterm.Warning.Printf("Failed to extract metadata for document %d: %v\n", doc.ID, err)
+			stats.incError(doc.ID)
+		} else {
+			stats.incSuccess(doc.ID)
+		}
+		progressBar.Increment()
+	}
+	progressBar.Stop()
+
+	stats.renderFinalSummary(len(documents))
+	return nil
+}
+
+// extractMetadataForDocument downloads doc, renders its first page, asks
+// the LLM for the built-in metadata_extraction schema's fields, and PATCHes
+// the resolved correspondent/document type/custom fields onto it.
+func (e *ActionExecutor) extractMetadataForDocument(doc internal.Document) error {
+	pdfBytes, err := e.paperlessClient.DownloadDocument(e.ctx, doc.ID)
+	if err != nil {
+		return fmt.Errorf("failed to download PDF: %w", err)
+	}
+
+	jpegData, err := internal.RenderPageToJpg(e.ctx, e.config, pdfBytes, 0)
+	if err != nil {
+		return fmt.Errorf("failed to render page: %w", err)
+	}
+
+	fields, err := e.llmClient.ExtractFields(e.ctx, doc.ID, jpegData, nil)
+	if err != nil {
+		return fmt.Errorf("failed to extract fields: %w", err)
+	}
+
+	updates, err := e.resolveMetadataUpdates(fields)
+	if err != nil {
+		return fmt.Errorf("failed to resolve extracted fields: %w", err)
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+
+	pterm.Info.Printf("Extracted metadata for document %d (%s): %v\n", doc.ID, doc.Title, updates)
+	return e.paperlessClient.UpdateDocument(e.ctx, doc.ID, updates)
+}
+
+// metadataCustomFields maps the metadata_extraction schema's free-text
+// fields (everything but correspondent/document_type/tags, which already
+// have first-class Paperless resources) to the "string" custom fields
+// ExtractMetadataAction creates for them on first use.
+var metadataCustomFields = []string{"document_date", "amount", "currency", "invoice_number", "iban"}
+
+// resolveMetadataUpdates turns fields (ExtractFields's result, shaped by
+// schema_metadata.json) into the map UpdateDocument's PATCH expects:
+// correspondent/document_type/tags resolve to Paperless resources the same
+// way ApplyRulesAction's resolveUpdates does, and the remaining fields
+// become custom fields. Blank or missing values are skipped rather than
+// overwriting a document with empty data.
+func (e *ActionExecutor) resolveMetadataUpdates(fields map[string]interface{}) (map[string]interface{}, error) {
+	updates := map[string]interface{}{}
+
+	if v, _ := fields["correspondent"].(string); v != "" {
+		id, err := e.paperlessClient.GetOrCreateCorrespondent(e.ctx, v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve correspondent %q: %w", v, err)
+		}
+		updates["correspondent"] = id
+	}
+
+	if v, _ := fields["document_type"].(string); v != "" {
+		id, err := e.paperlessClient.GetOrCreateDocumentType(e.ctx, v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve document type %q: %w", v, err)
+		}
+		updates["document_type"] = id
+	}
+
+	if tags, ok := fields["tags"].([]interface{}); ok && len(tags) > 0 {
+		tagIDs := make([]int, 0, len(tags))
+		for _, t := range tags {
+			name, _ := t.(string)
+			if name == "" {
+				continue
+			}
+			id, err := e.paperlessClient.GetOrCreateTag(e.ctx, name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve tag %q: %w", name, err)
+			}
+			tagIDs = append(tagIDs, id)
+		}
+		if len(tagIDs) > 0 {
+			updates["tags"] = tagIDs
+		}
+	}
+
+	var customFields []map[string]interface{}
+	for _, name := range metadataCustomFields {
+		v, _ := fields[name].(string)
+		if v == "" {
+			continue
+		}
+		id, err := e.paperlessClient.GetOrCreateCustomField(e.ctx, name, "string")
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve custom field %q: %w", name, err)
+		}
+		customFields = append(customFields, map[string]interface{}{"field": id, "value": v})
+	}
+	if len(customFields) > 0 {
+		updates["custom_fields"] = customFields
+	}
+
+	return updates, nil
+}
@@ -1,8 +1,11 @@
 package processor
 
 import (
+	"context"
 	"fmt"
 	"sort"
+	"sync"
+	"sync/atomic"
 
 	"github.com/dhcgn/paperless-ngx-privatemode-ai/internal"
 	"github.com/pterm/pterm"
@@ -16,22 +19,29 @@ func (a *SetOcrInContentAction) Description() string {
 }
 
 func (a *SetOcrInContentAction) Execute(executor *ActionExecutor) error {
-	// Get all documents
+	// Stream every document in, rather than holding a whole (possibly
+	// 50k-document) instance's documents in memory at once. ctx is
+	// cancelled on every return path so the IterateDocuments producer
+	// goroutine never blocks forever on a send FilterDocuments stopped
+	// reading (e.g. an invalid content pattern).
+	ctx, cancel := context.WithCancel(executor.ctx)
+	defer cancel()
+
 	pterm.Info.Println("Fetching documents from Paperless NGX...")
-	documents, err := executor.paperlessClient.GetDocuments()
-	if err != nil {
-		return fmt.Errorf("failed to get documents: %w", err)
-	}
-	pterm.Success.Printf("Fetched %d documents\n", len(documents))
+	documents, errCh := executor.paperlessClient.IterateDocuments(ctx, internal.IterateOptions{})
 
 	// Filter documents by content pattern
 	pterm.Info.Println("Filtering documents by content pattern...")
-	filteredDocs, err := executor.paperlessClient.FilterDocuments(documents, internal.FilterTypeContent)
+	filteredDocs, totalSeen, err := executor.paperlessClient.FilterDocuments(ctx, documents, internal.FilterTypeContent, executor.llmClient)
+	if err == nil {
+		err = <-errCh
+	}
 	if err != nil {
 		return fmt.Errorf("failed to filter documents: %w", err)
 	}
 	pterm.Success.Printf("Found %d documents matching content patterns\n", len(filteredDocs))
 
+	filteredDocs = executor.skipResumed(filteredDocs)
 	if len(filteredDocs) == 0 {
 		pterm.Warning.Println("No documents found matching the content patterns")
 		return nil
@@ -39,23 +49,31 @@ func (a *SetOcrInContentAction) Execute(executor *ActionExecutor) error {
 
 	// Display bar chart with document counts
 	bars := []pterm.Bar{
-		{Label: "All", Value: len(documents), Style: pterm.NewStyle(pterm.FgGray)},
+		{Label: "All", Value: totalSeen, Style: pterm.NewStyle(pterm.FgGray)},
 		{Label: "Found", Value: len(filteredDocs), Style: pterm.NewStyle(pterm.FgGreen)},
 	}
 	pterm.DefaultBarChart.WithHorizontal().WithBars(bars).WithShowValue().Render()
 
-	// Ask for confirmation
-	confirmed, err := pterm.DefaultInteractiveConfirm.
-		WithDefaultValue(false).
-		WithDefaultText("Do you want to make ocr for these documents using LLM?").
-		Show()
-	if err != nil {
-		return fmt.Errorf("failed to get confirmation: %w", err)
+	executor.maybePageFilteredDocuments(filteredDocs)
+
+	// Ask for confirmation, unless nothing is around to answer it.
+	if !executor.nonInteractive {
+		confirmed, err := pterm.DefaultInteractiveConfirm.
+			WithDefaultValue(false).
+			WithDefaultText("Do you want to make ocr for these documents using LLM?").
+			Show()
+		if err != nil {
+			return fmt.Errorf("failed to get confirmation: %w", err)
+		}
+
+		if !confirmed {
+			pterm.Info.Println("Operation cancelled by user")
+			return nil
+		}
 	}
 
-	if !confirmed {
-		pterm.Info.Println("Operation cancelled by user")
-		return nil
+	if executor.nonInteractive {
+		return executor.processOCRGeneration(filteredDocs, nil)
 	}
 
 	// Process documents
@@ -64,98 +82,155 @@ func (a *SetOcrInContentAction) Execute(executor *ActionExecutor) error {
 	})
 }
 
+// processOCRGeneration runs documents through a download -> render -> OCR ->
+// title pipeline, with each stage backed by its own pool of goroutines (see
+// processor/pipeline.go and config.ProcessingConfig.Workers). The final
+// step - user interaction and writing the result back to Paperless - stays
+// on this goroutine so prompts and progress stats never interleave.
 func (e *ActionExecutor) processOCRGeneration(documents []internal.Document, userCallback func(internal.Document, string, string) bool) error {
-	stats := &ProgressStats{
-		processed: 0,
-		success:   0,
-		errors:    0,
-		skipped:   0,
-		total:     len(documents),
-	}
-
+	stats := &ProgressStats{total: len(documents)}
+	e.stats = stats
 	pterm.Info.Println("Starting OCR generation process...")
+	e.emitEvent(map[string]interface{}{"event": "run_started", "action": "ocr", "total": len(documents)})
 
-	for _, doc := range documents {
-		pterm.Info.Printf("Generating ocr for document '%s' (id: %d, link: %s)\n", doc.Title, doc.ID, e.config.CreateUrl(doc.ID))
+	wc := e.config.Processing.Workers
+	downloadIn := make(chan *ocrJob, len(documents))
+	extractIn := make(chan *ocrJob, len(documents))
+	titleIn := make(chan *ocrJob, len(documents))
+	updateIn := make(chan *ocrJob, len(documents))
 
-		// Download document pdf
-		pdfBytes, err := e.paperlessClient.DownloadDocument(doc.ID)
-		if err != nil {
-			pterm.Warning.Printf("Failed to download PDF for document %d: %v\n", doc.ID, err)
-			stats.errors++
-			stats.processed++
-			stats.renderProgressChart()
-			continue
-		}
+	activity := &stageActivity{}
+	var downloadWg, extractWg, titleWg sync.WaitGroup
 
-		// Convert first page to JPEG
-		jpegData, err := internal.RenderPageToJpg(e.config, pdfBytes, 0)
-		if err != nil {
-			pterm.Warning.Printf("Failed to render page to JPG for document %d: %v\n", doc.ID, err)
-			stats.errors++
-			stats.processed++
-			stats.renderProgressChart()
-			continue
-		}
-
-		// Extract content using LLM
-		newContent, err := e.llmClient.MakeOcr(jpegData)
-		if err != nil {
-			pterm.Warning.Printf("Failed to extract content for document %d: %v\n", doc.ID, err)
-			stats.errors++
-			stats.processed++
-			stats.renderProgressChart()
-			continue
-		}
+	for i := 0; i < e.documentWorkerCount(wc.Download, defaultDownloadWorkers); i++ {
+		downloadWg.Add(1)
+		go e.ocrDownloadWorker(downloadIn, extractIn, &downloadWg, activity)
+	}
+	// Rendering now happens page-by-page inside ocrExtractWorker (see
+	// OcrPipeline), so the render stage's worker count still governs how
+	// many documents are rendered+OCR'd concurrently.
+	for i := 0; i < e.documentWorkerCount(wc.Render, defaultRenderWorkers); i++ {
+		extractWg.Add(1)
+		go e.ocrExtractWorker(extractIn, titleIn, &extractWg, activity)
+	}
+	for i := 0; i < e.documentWorkerCount(wc.LLM, defaultLLMWorkers); i++ {
+		titleWg.Add(1)
+		go e.ocrTitleWorker(titleIn, updateIn, &titleWg, activity)
+	}
 
-		// Generate new titles using LLM
-		captions, err := e.llmClient.GenerateTitleFromContent(newContent)
-		if err != nil {
-			pterm.Warning.Printf("Failed to generate title for document %d: %v\n", doc.ID, err)
-			stats.errors++
-			stats.processed++
-			stats.renderProgressChart()
-			continue
-		}
+	go func() { downloadWg.Wait(); close(extractIn) }()
+	go func() { extractWg.Wait(); close(titleIn) }()
+	go func() { titleWg.Wait(); close(updateIn) }()
 
-		if len(captions.Captions) == 0 {
-			pterm.Warning.Printf("No titles generated for document %d\n", doc.ID)
-			stats.errors++
-			stats.processed++
-			stats.renderProgressChart()
-			continue
+	go func() {
+		for _, doc := range documents {
+			downloadIn <- &ocrJob{doc: doc}
 		}
-
-		// Sort captions by score (highest score first)
-		sort.Slice(captions.Captions, func(i, j int) bool {
-			return captions.Captions[i].Score > captions.Captions[j].Score
-		})
-
-		newTitle := captions.Captions[0].Caption
-
-		if userCallback != nil {
-			pterm.Info.Println("Start User Interaction")
-			if !userCallback(doc, newContent, newTitle) {
-				pterm.Warning.Println("User cancelled this operation")
-				stats.skipped++
-				stats.processed++
-				stats.renderProgressChart()
-				continue
-			}
-			pterm.Info.Println("End of User Interaction")
+		close(downloadIn)
+	}()
+
+	// An interactive run gets a live per-stage dashboard; an autonomous or
+	// webhook-driven run keeps the simpler ticking aggregate chart since no
+	// one is watching the terminal.
+	var dashboard *stageDashboard
+	if !e.autonomous && !e.jsonOutput {
+		gauges := []stageGauge{
+			{label: "Download", count: func() int64 { return atomic.LoadInt64(&activity.download) }},
+			{label: "Render + OCR", count: func() int64 { return atomic.LoadInt64(&activity.render) }},
+			{label: "Title", count: func() int64 { return atomic.LoadInt64(&activity.title) }},
 		}
-
-		if err := SetContentOfPaperlessDocument(e, doc.ID, newContent); err != nil {
-			pterm.Warning.Printf("Failed to update document %d: %v\n", doc.ID, err)
-			stats.errors++
+		if d, err := newStageDashboard(gauges, stats); err != nil {
+			pterm.Warning.Printf("Failed to start live dashboard, falling back to plain progress: %v\n", err)
 		} else {
-			stats.success++
+			dashboard = d
 		}
+	}
+
+	var stopReporter func()
+	if dashboard == nil {
+		stopReporter = startProgressReporter(stats)
+	}
 
-		stats.processed++
-		stats.renderProgressChart()
+	for job := range updateIn {
+		e.finishOCRJob(job, userCallback, stats)
+	}
+
+	if dashboard != nil {
+		dashboard.stop()
+	} else {
+		stopReporter()
 	}
 
 	stats.renderFinalSummary(len(documents))
+	if e.dryRun {
+		renderChangesTable(e.Changes(), e.config.Processing.DryRun.ContextChars)
+	}
+	total, _, success, errors, skipped := stats.snapshot()
+	e.emitEvent(map[string]interface{}{"event": "run_finished", "action": "ocr", "total": total, "success": success, "errors": errors, "skipped": skipped})
 	return nil
 }
+
+// finishOCRJob applies the result of the OCR pipeline for a single document:
+// it reports pipeline errors, asks userCallback (if any) to confirm the new
+// content and title, and writes the content back to Paperless. It always
+// runs on the caller's goroutine so stats updates and user interaction are
+// serialized even though the upstream stages are concurrent.
+func (e *ActionExecutor) finishOCRJob(job *ocrJob, userCallback func(internal.Document, string, string) bool, stats *ProgressStats) {
+	doc := job.doc
+	pterm.Info.Printf("Generating ocr for document '%s' (id: %d, link: %s)\n", doc.Title, doc.ID, e.config.CreateUrl(doc.ID))
+
+	if job.err != nil {
+		pterm.Warning.Printf("Failed to process document %d: %v\n", doc.ID, job.err)
+		e.emitEvent(map[string]interface{}{"event": "error", "id": doc.ID, "error": job.err.Error()})
+		stats.incError(doc.ID)
+		e.persistCheckpoint()
+		return
+	}
+
+	if len(job.captions.Captions) == 0 {
+		pterm.Warning.Printf("No titles generated for document %d\n", doc.ID)
+		e.emitEvent(map[string]interface{}{"event": "error", "id": doc.ID, "error": "no titles generated"})
+		stats.incError(doc.ID)
+		e.persistCheckpoint()
+		return
+	}
+
+	// Sort captions by score (highest score first)
+	sort.Slice(job.captions.Captions, func(i, j int) bool {
+		return job.captions.Captions[i].Score > job.captions.Captions[j].Score
+	})
+
+	newTitle := job.captions.Captions[0].Caption
+
+	if userCallback != nil {
+		pterm.Info.Println("Start User Interaction")
+		if !userCallback(doc, job.content, newTitle) {
+			pterm.Warning.Println("User cancelled this operation")
+			stats.incSkipped(doc.ID)
+			e.persistCheckpoint()
+			return
+		}
+		pterm.Info.Println("End of User Interaction")
+	}
+
+	if err := SetContentOfPaperlessDocument(e.ctx, e, doc.ID, job.content); err != nil {
+		pterm.Warning.Printf("Failed to update document %d: %v\n", doc.ID, err)
+		e.emitEvent(map[string]interface{}{"event": "error", "id": doc.ID, "error": err.Error()})
+		stats.incError(doc.ID)
+		e.persistCheckpoint()
+		return
+	}
+	e.recordChange(doc.ID, "content", doc.Content, job.content, "LLM OCR content extraction", job.captions.Captions[0].Score)
+	e.emitEvent(map[string]interface{}{"event": "content_changed", "id": doc.ID, "new_title": newTitle, "score": job.captions.Captions[0].Score})
+
+	if e.config.Processing.OCR.HOCRDir != "" {
+		if path, err := internal.WriteHOCR(e.config.Processing.OCR.HOCRDir, doc.ID, job.pages); err != nil {
+			pterm.Warning.Printf("Failed to write hOCR sidecar for document %d: %v\n", doc.ID, err)
+		} else {
+			pterm.Info.Printf("Wrote hOCR sidecar to %s\n", path)
+		}
+	}
+
+	stats.incSuccess(doc.ID)
+	e.persistCheckpoint()
+}
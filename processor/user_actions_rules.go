@@ -0,0 +1,152 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dhcgn/paperless-ngx-privatemode-ai/internal"
+	"github.com/dhcgn/paperless-ngx-privatemode-ai/internal/rules"
+	"github.com/pterm/pterm"
+)
+
+// ApplyRulesAction evaluates config.Rules.Rules against every document and,
+// for the first rule that matches, PATCHes the resolved
+// correspondent/document type/tags onto it in Paperless. This is the
+// auto-classifier counterpart to the title/content-only actions above.
+type ApplyRulesAction struct{}
+
+func (a *ApplyRulesAction) Description() string {
+	return "Apply routing rules to set correspondents, document types, and tags"
+}
+
+func (a *ApplyRulesAction) Execute(executor *ActionExecutor) error {
+	compiled, err := rules.Compile(executor.config.Rules.Rules)
+	if err != nil {
+		return fmt.Errorf("failed to compile rules: %w", err)
+	}
+	if len(compiled) == 0 {
+		pterm.Warning.Println("No rules configured under rules.rules")
+		return nil
+	}
+
+	pterm.Info.Println("Fetching documents from Paperless NGX...")
+	documents, err := executor.paperlessClient.GetAllDocuments(executor.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get documents: %w", err)
+	}
+	pterm.Success.Printf("Fetched %d documents\n", len(documents))
+
+	classifier := &llmClassifier{llmClient: executor.llmClient, ctx: executor.ctx}
+
+	stats := &ProgressStats{total: len(documents)}
+	progressBar, _ := pterm.DefaultProgressbar.WithTitle("Applying rules").WithTotal(len(documents)).Start()
+	for _, doc := range documents {
+		matched, err := applyRulesToDocument(executor, compiled, doc, classifier)
+		switch {
+		case err != nil:
+			pterm.Warning.Printf("Failed to apply rules to document %d: %v\n", doc.ID, err)
+			stats.incError(doc.ID)
+		case matched:
+			stats.incSuccess(doc.ID)
+		default:
+			stats.incSkipped(doc.ID)
+		}
+		progressBar.Increment()
+	}
+	progressBar.Stop()
+
+	stats.renderFinalSummary(len(documents))
+	return nil
+}
+
+// applyRulesToDocument evaluates compiled against doc in order and applies
+// the first rule that matches, reporting whether any rule matched.
+func applyRulesToDocument(executor *ActionExecutor, compiled []*rules.Rule, doc internal.Document, classifier rules.Classifier) (bool, error) {
+	view := rules.Document{ID: doc.ID, Title: doc.Title, Content: doc.Content, CreatedDate: doc.CreatedDate}
+
+	for _, rule := range compiled {
+		action, matched, err := rule.Match(view, classifier)
+		if err != nil {
+			return false, fmt.Errorf("rule %q: %w", rule.Name(), err)
+		}
+		if !matched {
+			continue
+		}
+
+		updates, err := resolveUpdates(executor, action)
+		if err != nil {
+			return false, fmt.Errorf("rule %q: %w", rule.Name(), err)
+		}
+		if len(updates) == 0 {
+			return true, nil
+		}
+
+		pterm.Info.Printf("Rule %q matched document %d (%s): %v\n", rule.Name(), doc.ID, doc.Title, updates)
+		if err := executor.paperlessClient.UpdateDocument(executor.ctx, doc.ID, updates); err != nil {
+			return false, fmt.Errorf("failed to update document: %w", err)
+		}
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// resolveUpdates turns a rules.Action's correspondent/document type/tag
+// names into the IDs Paperless NGX's PATCH /api/documents/ expects,
+// creating any that don't already exist.
+func resolveUpdates(executor *ActionExecutor, action rules.Action) (map[string]interface{}, error) {
+	updates := map[string]interface{}{}
+
+	if action.Correspondent != "" {
+		id, err := executor.paperlessClient.GetOrCreateCorrespondent(executor.ctx, action.Correspondent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve correspondent %q: %w", action.Correspondent, err)
+		}
+		updates["correspondent"] = id
+	}
+
+	if action.DocumentType != "" {
+		id, err := executor.paperlessClient.GetOrCreateDocumentType(executor.ctx, action.DocumentType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve document type %q: %w", action.DocumentType, err)
+		}
+		updates["document_type"] = id
+	}
+
+	if len(action.Tags) > 0 {
+		tagIDs := make([]int, 0, len(action.Tags))
+		for _, tagName := range action.Tags {
+			id, err := executor.paperlessClient.GetOrCreateTag(executor.ctx, tagName)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve tag %q: %w", tagName, err)
+			}
+			tagIDs = append(tagIDs, id)
+		}
+		updates["tags"] = tagIDs
+	}
+
+	return updates, nil
+}
+
+// llmClassifier adapts internal.LLMClient to rules.Classifier for the
+// {{llm:"..."}} placeholder. LLMClient has no generic free-text chat
+// method, so this reuses GenerateTitleFromContent - prompt prepended to the
+// document content - and takes its top caption as the answer.
+type llmClassifier struct {
+	llmClient *internal.LLMClient
+	ctx       context.Context
+}
+
+func (c *llmClassifier) Classify(doc rules.Document, prompt string) (string, error) {
+	if c.llmClient == nil {
+		return "", fmt.Errorf("no LLM client configured")
+	}
+	captions, err := c.llmClient.GenerateTitleFromContent(c.ctx, doc.ID, prompt+"\n\n"+doc.Content)
+	if err != nil {
+		return "", err
+	}
+	if len(captions.Captions) == 0 {
+		return "", fmt.Errorf("LLM returned no classification")
+	}
+	return captions.Captions[0].Caption, nil
+}
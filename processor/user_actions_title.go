@@ -1,8 +1,11 @@
 package processor
 
 import (
+	"context"
 	"fmt"
 	"sort"
+	"sync"
+	"sync/atomic"
 
 	"github.com/dhcgn/paperless-ngx-privatemode-ai/internal"
 	"github.com/pterm/pterm"
@@ -16,22 +19,29 @@ func (a *SetTitleAction) Description() string {
 }
 
 func (a *SetTitleAction) Execute(executor *ActionExecutor) error {
-	// Get all documents
+	// Stream every document in, rather than holding a whole (possibly
+	// 50k-document) instance's documents in memory at once. ctx is
+	// cancelled on every return path so the IterateDocuments producer
+	// goroutine never blocks forever on a send FilterDocuments stopped
+	// reading (e.g. an invalid title pattern).
+	ctx, cancel := context.WithCancel(executor.ctx)
+	defer cancel()
+
 	pterm.Info.Println("Fetching documents from Paperless NGX...")
-	documents, err := executor.paperlessClient.GetDocuments()
-	if err != nil {
-		return fmt.Errorf("failed to get documents: %w", err)
-	}
-	pterm.Success.Printf("Fetched %d documents\n", len(documents))
+	documents, errCh := executor.paperlessClient.IterateDocuments(ctx, internal.IterateOptions{})
 
 	// Filter documents by title pattern
 	pterm.Info.Println("Filtering documents by title pattern...")
-	filteredDocs, err := executor.paperlessClient.FilterDocuments(documents, internal.FilterTypeTitle)
+	filteredDocs, totalSeen, err := executor.paperlessClient.FilterDocuments(ctx, documents, internal.FilterTypeTitle, executor.llmClient)
+	if err == nil {
+		err = <-errCh
+	}
 	if err != nil {
 		return fmt.Errorf("failed to filter documents: %w", err)
 	}
 	pterm.Success.Printf("Found %d documents matching title patterns\n", len(filteredDocs))
 
+	filteredDocs = executor.skipResumed(filteredDocs)
 	if len(filteredDocs) == 0 {
 		pterm.Warning.Println("No documents found matching the title patterns")
 		return nil
@@ -39,13 +49,15 @@ func (a *SetTitleAction) Execute(executor *ActionExecutor) error {
 
 	// Display bar chart with document counts
 	bars := []pterm.Bar{
-		{Label: "All", Value: len(documents), Style: pterm.NewStyle(pterm.FgGray)},
+		{Label: "All", Value: totalSeen, Style: pterm.NewStyle(pterm.FgGray)},
 		{Label: "Found", Value: len(filteredDocs), Style: pterm.NewStyle(pterm.FgGreen)},
 	}
 	pterm.DefaultBarChart.WithHorizontal().WithBars(bars).WithShowValue().Render()
 
+	executor.maybePageFilteredDocuments(filteredDocs)
+
 	// Ask for confirmation
-	if !executor.autonomous {
+	if !executor.autonomous && !executor.nonInteractive {
 		confirmed, err := pterm.DefaultInteractiveConfirm.
 			WithDefaultValue(false).
 			WithDefaultText("Do you want to generate new titles for these documents using LLM?").
@@ -60,6 +72,10 @@ func (a *SetTitleAction) Execute(executor *ActionExecutor) error {
 		}
 	}
 
+	if executor.nonInteractive {
+		return executor.processDocumentsForTitleGeneration(filteredDocs, nil)
+	}
+
 	// Process documents
 	return executor.processDocumentsForTitleGeneration(filteredDocs, func(doc internal.Document, captionResp internal.CaptionResponse) (string, bool) {
 		// Show document summary first
@@ -68,7 +84,7 @@ func (a *SetTitleAction) Execute(executor *ActionExecutor) error {
 		}
 
 	AskForTitleSelection:
-		selectedOption, userSelectedTitle, err := askUserForTitleSelection(captionResp, doc.Title, doc.ID, executor.config.CreateUrl(doc.ID))
+		selectedOption, userSelectedTitle, err := askUserForTitleSelection(executor, captionResp, doc.Title, doc.ID, executor.config.CreateUrl(doc.ID))
 		if err != nil {
 			return "", false
 		}
@@ -100,11 +116,12 @@ func (a *SetTitleAction) Execute(executor *ActionExecutor) error {
 			captionResp = *captions
 
 			if executor.askUserForSetContent(doc, ocr) {
-				err := SetContentOfPaperlessDocument(executor, doc.ID, ocr)
+				err := SetContentOfPaperlessDocument(executor.ctx, executor, doc.ID, ocr)
 				if err != nil {
 					pterm.Error.Printf("Failed to set content for document %d: %v\n", doc.ID, err)
 					return "", false
 				}
+				executor.recordChange(doc.ID, "content", doc.Content, ocr, "OCR content extraction (retry before title selection)", 0)
 				pterm.Success.Printf("Content set for document '%s' (id: %d)\n", doc.Title, doc.ID)
 			}
 			goto AskForTitleSelection // Re-ask for title selection with new captions
@@ -115,78 +132,135 @@ func (a *SetTitleAction) Execute(executor *ActionExecutor) error {
 	})
 }
 
+// processDocumentsForTitleGeneration runs documents through a pool of
+// title-generation workers (see processor/pipeline.go and
+// config.ProcessingConfig.Workers.LLM), then selects and writes back the
+// title for each one on this goroutine so user prompts and progress stats
+// never interleave.
 func (e *ActionExecutor) processDocumentsForTitleGeneration(documents []internal.Document, userCallback func(internal.Document, internal.CaptionResponse) (string, bool)) error {
-	stats := &ProgressStats{
-		processed: 0,
-		success:   0,
-		errors:    0,
-		skipped:   0,
-		total:     len(documents),
-	}
-
+	stats := &ProgressStats{total: len(documents)}
+	e.stats = stats
 	pterm.Info.Println("Starting title generation process...")
+	e.emitEvent(map[string]interface{}{"event": "run_started", "action": "title", "total": len(documents)})
 
-	for _, doc := range documents {
-		// Generate new titles using LLM
-		pterm.Info.Printf("Generating title for document '%s' (id: %d, link: %s)\n", doc.Title, doc.ID, e.config.CreateUrl(doc.ID))
-		captions, err := e.llmClient.GenerateTitleFromContent(doc.Content)
-		if err != nil {
-			pterm.Warning.Printf("Failed to generate title for document %d: %v\n", doc.ID, err)
-			stats.errors++
-			stats.processed++
-			stats.renderProgressChart()
-			continue
+	in := make(chan *titleJob, len(documents))
+	out := make(chan *titleJob, len(documents))
+
+	activity := &stageActivity{}
+	var wg sync.WaitGroup
+	for i := 0; i < e.documentWorkerCount(e.config.Processing.Workers.LLM, defaultLLMWorkers); i++ {
+		wg.Add(1)
+		go e.titleGenerationWorker(in, out, &wg, activity)
+	}
+	go func() { wg.Wait(); close(out) }()
+
+	go func() {
+		for _, doc := range documents {
+			in <- &titleJob{doc: doc}
 		}
+		close(in)
+	}()
 
-		if len(captions.Captions) == 0 {
-			pterm.Warning.Printf("No titles generated for document %d\n", doc.ID)
-			stats.errors++
-			stats.processed++
-			stats.renderProgressChart()
-			continue
+	// An interactive run gets a live per-stage dashboard; an autonomous or
+	// webhook-driven run keeps the simpler ticking aggregate chart since no
+	// one is watching the terminal.
+	var dashboard *stageDashboard
+	if !e.autonomous && !e.jsonOutput {
+		gauges := []stageGauge{
+			{label: "Title", count: func() int64 { return atomic.LoadInt64(&activity.title) }},
+		}
+		if d, err := newStageDashboard(gauges, stats); err != nil {
+			pterm.Warning.Printf("Failed to start live dashboard, falling back to plain progress: %v\n", err)
+		} else {
+			dashboard = d
 		}
+	}
 
-		// Sort captions by score (highest score first)
-		sort.Slice(captions.Captions, func(i, j int) bool {
-			return captions.Captions[i].Score > captions.Captions[j].Score
-		})
+	var stopReporter func()
+	if dashboard == nil {
+		stopReporter = startProgressReporter(stats)
+	}
 
-		var selectedTitle string
-		var userConfirmed bool
+	for job := range out {
+		e.finishTitleJob(job, userCallback, stats)
+	}
 
-		if userCallback != nil && !e.autonomous {
-			pterm.Info.Println("Start User Interaction")
-			selectedTitle, userConfirmed = userCallback(doc, captions)
-			if !userConfirmed {
-				pterm.Warning.Println("User cancelled this operation")
-				stats.skipped++
-				stats.processed++
-				stats.renderProgressChart()
-				continue
-			}
-			pterm.Info.Println("End of User Interaction")
-		} else {
-			// Use the first generated title if no callback
-			selectedTitle = captions.Captions[0].Caption
+	if dashboard != nil {
+		dashboard.stop()
+	} else {
+		stopReporter()
+	}
 
-			if e.autonomous {
-				selectedTitle = selectedTitle + " (auto-generated)"
-			}
+	stats.renderFinalSummary(len(documents))
+	if e.dryRun {
+		renderChangesTable(e.Changes(), e.config.Processing.DryRun.ContextChars)
+	}
+	total, _, success, errors, skipped := stats.snapshot()
+	e.emitEvent(map[string]interface{}{"event": "run_finished", "action": "title", "total": total, "success": success, "errors": errors, "skipped": skipped})
+	return nil
+}
+
+// finishTitleJob applies the result of title generation for a single
+// document: it reports errors, picks a title (via userCallback or the
+// top-scored caption), and writes it back to Paperless.
+func (e *ActionExecutor) finishTitleJob(job *titleJob, userCallback func(internal.Document, internal.CaptionResponse) (string, bool), stats *ProgressStats) {
+	doc := job.doc
+	pterm.Info.Printf("Generating title for document '%s' (id: %d, link: %s)\n", doc.Title, doc.ID, e.config.CreateUrl(doc.ID))
+
+	if job.err != nil {
+		pterm.Warning.Printf("Failed to generate title for document %d: %v\n", doc.ID, job.err)
+		e.emitEvent(map[string]interface{}{"event": "error", "id": doc.ID, "error": job.err.Error()})
+		stats.incError(doc.ID)
+		e.persistCheckpoint()
+		return
+	}
 
-			userConfirmed = true
+	if len(job.captions.Captions) == 0 {
+		pterm.Warning.Printf("No titles generated for document %d\n", doc.ID)
+		e.emitEvent(map[string]interface{}{"event": "error", "id": doc.ID, "error": "no titles generated"})
+		stats.incError(doc.ID)
+		e.persistCheckpoint()
+		return
+	}
+
+	// Sort captions by score (highest score first)
+	sort.Slice(job.captions.Captions, func(i, j int) bool {
+		return job.captions.Captions[i].Score > job.captions.Captions[j].Score
+	})
+
+	var selectedTitle string
+
+	if userCallback != nil && !e.autonomous {
+		pterm.Info.Println("Start User Interaction")
+		var userConfirmed bool
+		selectedTitle, userConfirmed = userCallback(doc, *job.captions)
+		if !userConfirmed {
+			pterm.Warning.Println("User cancelled this operation")
+			stats.incSkipped(doc.ID)
+			e.persistCheckpoint()
+			return
 		}
+		pterm.Info.Println("End of User Interaction")
+	} else {
+		// Use the first generated title if no callback
+		selectedTitle = job.captions.Captions[0].Caption
 
-		if err := SetTitleOfPaperlessDocument(e, doc.ID, selectedTitle); err != nil {
-			pterm.Warning.Printf("Failed to update document %d: %v\n", doc.ID, err)
-			stats.errors++
-		} else {
-			stats.success++
+		if e.autonomous || e.nonInteractive {
+			selectedTitle = selectedTitle + " (auto-generated)"
 		}
+	}
 
-		stats.processed++
-		stats.renderProgressChart()
+	oldTitle := doc.Title
+	if err := SetTitleOfPaperlessDocument(e.ctx, e, doc.ID, selectedTitle); err != nil {
+		pterm.Warning.Printf("Failed to update document %d: %v\n", doc.ID, err)
+		e.emitEvent(map[string]interface{}{"event": "error", "id": doc.ID, "error": err.Error()})
+		stats.incError(doc.ID)
+		e.persistCheckpoint()
+		return
 	}
+	e.recordChange(doc.ID, "title", oldTitle, selectedTitle, "LLM title generation", job.captions.Captions[0].Score)
+	e.emitEvent(map[string]interface{}{"event": "title_changed", "id": doc.ID, "old": oldTitle, "new": selectedTitle, "score": job.captions.Captions[0].Score})
 
-	stats.renderFinalSummary(len(documents))
-	return nil
+	stats.incSuccess(doc.ID)
+	e.persistCheckpoint()
 }